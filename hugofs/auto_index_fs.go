@@ -0,0 +1,159 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+)
+
+var (
+	_ afero.Fs      = (*autoIndexFs)(nil)
+	_ afero.Lstater = (*autoIndexFs)(nil)
+)
+
+type autoIndexFs struct {
+	afero.Fs
+	indexName string
+	generate  func(dir string) []byte
+}
+
+// NewAutoIndexFs creates a new filesystem that adds a virtual index file
+// named indexName to every directory without one. The content of the
+// virtual file is created by generate, which receives the directory name.
+// A real index file always wins over the virtual one.
+// Note that the virtual entry is only added to Readdir and Readdirnames
+// when reading the full directory, i.e. with count <= 0.
+func NewAutoIndexFs(fs afero.Fs, indexName string, generate func(dir string) []byte) afero.Fs {
+	return &autoIndexFs{Fs: fs, indexName: indexName, generate: generate}
+}
+
+// Stat returns the os.FileInfo structure describing a given file.
+func (fs *autoIndexFs) Stat(name string) (os.FileInfo, error) {
+	fi, err := fs.Fs.Stat(name)
+	if err != nil && fs.isVirtualIndex(name, err) {
+		return fs.newIndexFile(filepath.Dir(name)).Stat()
+	}
+	return fi, err
+}
+
+// LstatIfPossible returns the os.FileInfo structure describing a given file.
+func (fs *autoIndexFs) LstatIfPossible(name string) (os.FileInfo, bool, error) {
+	var (
+		fi  os.FileInfo
+		b   bool
+		err error
+	)
+
+	if lfs, ok := fs.Fs.(afero.Lstater); ok {
+		fi, b, err = lfs.LstatIfPossible(name)
+	} else {
+		fi, err = fs.Fs.Stat(name)
+	}
+
+	if err != nil && fs.isVirtualIndex(name, err) {
+		fi, err = fs.newIndexFile(filepath.Dir(name)).Stat()
+		return fi, false, err
+	}
+
+	return fi, b, err
+}
+
+// Open opens the named file for reading.
+func (fs *autoIndexFs) Open(name string) (afero.File, error) {
+	f, err := fs.Fs.Open(name)
+	if err != nil {
+		if fs.isVirtualIndex(name, err) {
+			return fs.newIndexFile(filepath.Dir(name)), nil
+		}
+		return nil, err
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	if fi.IsDir() {
+		return &autoIndexDir{File: f, fs: fs, name: name}, nil
+	}
+
+	return f, nil
+}
+
+// Name returns the name of this filesystem.
+func (fs *autoIndexFs) Name() string {
+	return "autoIndexFs"
+}
+
+func (fs *autoIndexFs) isVirtualIndex(name string, err error) bool {
+	if !os.IsNotExist(err) || filepath.Base(name) != fs.indexName {
+		return false
+	}
+	dir, err := fs.Fs.Stat(filepath.Dir(name))
+	return err == nil && dir.IsDir()
+}
+
+func (fs *autoIndexFs) newIndexFile(dir string) afero.File {
+	return newInMemoryFile(filepath.Join(dir, fs.indexName), fs.generate(dir))
+}
+
+type autoIndexDir struct {
+	afero.File
+	fs   *autoIndexFs
+	name string
+}
+
+// Readdir reads the directory, adding a virtual index file if needed.
+func (d *autoIndexDir) Readdir(count int) ([]os.FileInfo, error) {
+	fis, err := d.File.Readdir(count)
+	if err != nil || count > 0 {
+		return fis, err
+	}
+
+	for _, fi := range fis {
+		if realName(fi) == d.fs.indexName {
+			return fis, nil
+		}
+	}
+
+	fi, err := d.fs.newIndexFile(d.name).Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	return append(fis, fi), nil
+}
+
+// Readdirnames reads the directory names, adding a virtual index file if needed.
+func (d *autoIndexDir) Readdirnames(count int) ([]string, error) {
+	if count > 0 {
+		return d.File.Readdirnames(count)
+	}
+
+	fis, err := d.Readdir(count)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(fis))
+	for i, fi := range fis {
+		names[i] = fi.Name()
+	}
+
+	return names, nil
+}
@@ -0,0 +1,71 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutoIndexFs(t *testing.T) {
+	assert := require.New(t)
+
+	m := afero.NewMemMapFs()
+	afero.WriteFile(m, filepath.FromSlash("/content/withindex/_index.md"), []byte("real index"), 0755)
+	afero.WriteFile(m, filepath.FromSlash("/content/withindex/p1.md"), []byte("p1"), 0755)
+	afero.WriteFile(m, filepath.FromSlash("/content/noindex/p2.md"), []byte("p2"), 0755)
+
+	fs := NewAutoIndexFs(m, "_index.md", func(dir string) []byte {
+		return []byte("generated for " + filepath.ToSlash(dir))
+	})
+
+	names := func(dir string) []string {
+		f, err := fs.Open(filepath.FromSlash(dir))
+		assert.NoError(err)
+		defer f.Close()
+		names, err := f.Readdirnames(-1)
+		assert.NoError(err)
+		return names
+	}
+
+	assert.Equal([]string{"_index.md", "p1.md"}, names("/content/withindex"))
+	assert.Equal([]string{"p2.md", "_index.md"}, names("/content/noindex"))
+
+	b, err := afero.ReadFile(fs, filepath.FromSlash("/content/withindex/_index.md"))
+	assert.NoError(err)
+	assert.Equal("real index", string(b))
+
+	b, err = afero.ReadFile(fs, filepath.FromSlash("/content/noindex/_index.md"))
+	assert.NoError(err)
+	assert.Equal("generated for /content/noindex", string(b))
+
+	fi, err := fs.Stat(filepath.FromSlash("/content/noindex/_index.md"))
+	assert.NoError(err)
+	assert.Equal("_index.md", fi.Name())
+	assert.Equal(int64(len("generated for /content/noindex")), fi.Size())
+
+	fis, err := afero.ReadDir(fs, filepath.FromSlash("/content/noindex"))
+	assert.NoError(err)
+	assert.Len(fis, 2)
+
+	// Only directories get a virtual index.
+	_, err = fs.Stat(filepath.FromSlash("/content/nosuchdir/_index.md"))
+	assert.True(os.IsNotExist(err))
+	_, err = fs.Open(filepath.FromSlash("/content/noindex/other.md"))
+	assert.True(os.IsNotExist(err))
+}
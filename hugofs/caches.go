@@ -0,0 +1,55 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"sync"
+)
+
+// caches holds the caches registered with RegisterCache, so they can all be
+// cleared with FlushCaches.
+var caches = struct {
+	sync.Mutex
+	m map[Reseter]bool
+}{m: make(map[Reseter]bool)}
+
+// RegisterCache registers c to be reset in FlushCaches, e.g. a filesystem
+// created with NewContentCacheFs. Registration is up to the caller, as the
+// registry keeps c alive until the returned unregister func is called.
+func RegisterCache(c Reseter) (unregister func()) {
+	caches.Lock()
+	defer caches.Unlock()
+	caches.m[c] = true
+
+	return func() {
+		caches.Lock()
+		defer caches.Unlock()
+		delete(caches.m, c)
+	}
+}
+
+// FlushCaches resets all registered caches, e.g. between rebuilds in a
+// long-running server.
+func FlushCaches() {
+	caches.Lock()
+	registered := make([]Reseter, 0, len(caches.m))
+	for c := range caches.m {
+		registered = append(registered, c)
+	}
+	caches.Unlock()
+
+	for _, c := range registered {
+		c.Reset()
+	}
+}
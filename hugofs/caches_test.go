@@ -0,0 +1,84 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlushCaches(t *testing.T) {
+	assert := require.New(t)
+
+	m := &countingOpenFs{Fs: afero.NewMemMapFs()}
+	assert.NoError(afero.WriteFile(m, "p1.txt", []byte("v1"), 0755))
+
+	cfs := NewContentCacheFs(m, 10, 100)
+	unregisterCfs := RegisterCache(cfs.(Reseter))
+	defer unregisterCfs()
+	dfs := NewContentDedupFs(m)
+	unregisterDfs := RegisterCache(dfs)
+	defer unregisterDfs()
+
+	// Not registered.
+	unregistered := NewContentCacheFs(m, 10, 100)
+
+	read := func() string {
+		b, err := afero.ReadFile(cfs, "p1.txt")
+		assert.NoError(err)
+		return string(b)
+	}
+
+	hash := func() string {
+		h, err := dfs.ContentHash("p1.txt")
+		assert.NoError(err)
+		return h
+	}
+
+	assert.Equal("v1", read())
+	h1 := hash()
+	b, err := afero.ReadFile(unregistered, "p1.txt")
+	assert.NoError(err)
+	assert.Equal("v1", string(b))
+
+	// Change the file behind the caches' back, keeping size and modification time.
+	fi, err := m.Stat("p1.txt")
+	assert.NoError(err)
+	modTime := fi.ModTime()
+	assert.NoError(afero.WriteFile(m, "p1.txt", []byte("v2"), 0755))
+	assert.NoError(m.Chtimes("p1.txt", modTime, modTime))
+
+	assert.Equal("v1", read())
+	assert.Equal(h1, hash())
+
+	FlushCaches()
+
+	assert.Equal("v2", read())
+	assert.NotEqual(h1, hash())
+	b, err = afero.ReadFile(unregistered, "p1.txt")
+	assert.NoError(err)
+	assert.Equal("v1", string(b))
+
+	// Unregistered caches are left alone.
+	unregisterCfs()
+	assert.NoError(afero.WriteFile(m, "p1.txt", []byte("v3"), 0755))
+	FlushCaches()
+	assert.Equal("v2", read())
+
+	// Caches can still be reset individually.
+	cfs.(Reseter).Reset()
+	assert.Equal("v3", read())
+}
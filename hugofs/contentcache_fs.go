@@ -0,0 +1,291 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"container/list"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+var (
+	_ afero.Fs      = (*contentCacheFs)(nil)
+	_ afero.Lstater = (*contentCacheFs)(nil)
+	_ Reseter       = (*contentCacheFs)(nil)
+)
+
+// NewContentCacheFs creates a new filesystem that keeps the content of up to
+// maxEntries regular files in memory. Files larger than maxFileBytes are
+// not cached. The cache gets populated on Open; writes, renames and removals
+// done through this filesystem invalidate the affected entries. A file is not
+// cached while it is open for writing through this filesystem, and closing
+// it invalidates it.
+// Changes done directly in the underlying filesystem are not detected, use
+// Reset to clear the cache. The returned filesystem implements Reseter.
+func NewContentCacheFs(fs afero.Fs, maxEntries int, maxFileBytes int64) afero.Fs {
+	return &contentCacheFs{
+		Fs:           fs,
+		maxEntries:   maxEntries,
+		maxFileBytes: maxFileBytes,
+		entries:      make(map[string]*list.Element),
+		writers:      make(map[string]int),
+		lru:          list.New(),
+	}
+}
+
+type contentCacheFs struct {
+	afero.Fs
+
+	maxEntries   int
+	maxFileBytes int64
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+
+	// The number of open write handles per file.
+	writers map[string]int
+
+	// Incremented on every invalidation, so an Open racing with a write
+	// does not cache stale content.
+	gen uint64
+}
+
+type contentCacheEntry struct {
+	name    string
+	fi      os.FileInfo
+	content []byte
+}
+
+func (fs *contentCacheFs) Open(name string) (afero.File, error) {
+	key := filepath.Clean(name)
+
+	e, gen := fs.get(key)
+	if e != nil {
+		return newContentCacheFile(name, e), nil
+	}
+
+	f, err := fs.Fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	if !fi.Mode().IsRegular() || fi.Size() > fs.maxFileBytes || fs.maxEntries <= 0 {
+		return f, nil
+	}
+
+	content, err := afero.ReadAll(f)
+	f.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	e = &contentCacheEntry{name: key, fi: fi, content: content}
+	fs.add(e, gen)
+
+	return newContentCacheFile(name, e), nil
+}
+
+func (fs *contentCacheFs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	if !isWrite(flag) && flag&os.O_CREATE == 0 {
+		return fs.Open(name)
+	}
+	return fs.openWriter(name, func() (afero.File, error) {
+		return fs.Fs.OpenFile(name, flag, perm)
+	})
+}
+
+func (fs *contentCacheFs) Create(name string) (afero.File, error) {
+	return fs.openWriter(name, func() (afero.File, error) {
+		return fs.Fs.Create(name)
+	})
+}
+
+func (fs *contentCacheFs) openWriter(name string, open func() (afero.File, error)) (afero.File, error) {
+	key := filepath.Clean(name)
+
+	fs.mu.Lock()
+	fs.writers[key]++
+	fs.invalidateLocked(key)
+	fs.mu.Unlock()
+
+	f, err := open()
+	if err != nil {
+		fs.closeWriter(key)
+		return nil, err
+	}
+
+	return &contentCacheWriteFile{File: f, fs: fs, key: key}, nil
+}
+
+func (fs *contentCacheFs) closeWriter(key string) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if fs.writers[key]--; fs.writers[key] <= 0 {
+		delete(fs.writers, key)
+	}
+	fs.invalidateLocked(key)
+}
+
+func (fs *contentCacheFs) LstatIfPossible(name string) (os.FileInfo, bool, error) {
+	if lfs, ok := fs.Fs.(afero.Lstater); ok {
+		return lfs.LstatIfPossible(name)
+	}
+	fi, err := fs.Fs.Stat(name)
+	return fi, false, err
+}
+
+// Remove and the other methods below invalidate the cache after the change
+// in the underlying filesystem, so an Open racing with the change cannot
+// cache the old content after the invalidation.
+func (fs *contentCacheFs) Remove(name string) error {
+	err := fs.Fs.Remove(name)
+	fs.invalidate(name)
+	return err
+}
+
+func (fs *contentCacheFs) RemoveAll(path string) error {
+	err := fs.Fs.RemoveAll(path)
+	fs.invalidatePrefix(path)
+	return err
+}
+
+func (fs *contentCacheFs) Rename(oldname, newname string) error {
+	err := fs.Fs.Rename(oldname, newname)
+	fs.invalidatePrefix(oldname)
+	fs.invalidatePrefix(newname)
+	return err
+}
+
+func (fs *contentCacheFs) Chmod(name string, mode os.FileMode) error {
+	err := fs.Fs.Chmod(name, mode)
+	fs.invalidate(name)
+	return err
+}
+
+func (fs *contentCacheFs) Chtimes(name string, atime time.Time, mtime time.Time) error {
+	err := fs.Fs.Chtimes(name, atime, mtime)
+	fs.invalidate(name)
+	return err
+}
+
+func (fs *contentCacheFs) Name() string {
+	return "contentCacheFs"
+}
+
+// Reset clears the cache.
+func (fs *contentCacheFs) Reset() {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.entries = make(map[string]*list.Element)
+	fs.lru.Init()
+	fs.gen++
+}
+
+// get returns the cached entry for key, if any, and the current generation
+// to pass to add.
+func (fs *contentCacheFs) get(key string) (*contentCacheEntry, uint64) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	el, found := fs.entries[key]
+	if !found {
+		return nil, fs.gen
+	}
+	fs.lru.MoveToFront(el)
+	return el.Value.(*contentCacheEntry), fs.gen
+}
+
+// add adds e to the cache unless something was invalidated since gen
+// or the file is open for writing.
+func (fs *contentCacheFs) add(e *contentCacheEntry, gen uint64) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if fs.gen != gen || fs.writers[e.name] > 0 {
+		return
+	}
+
+	if el, found := fs.entries[e.name]; found {
+		el.Value = e
+		fs.lru.MoveToFront(el)
+		return
+	}
+
+	fs.entries[e.name] = fs.lru.PushFront(e)
+
+	for fs.lru.Len() > fs.maxEntries {
+		fs.removeElement(fs.lru.Back())
+	}
+}
+
+func (fs *contentCacheFs) invalidate(name string) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.invalidateLocked(filepath.Clean(name))
+}
+
+func (fs *contentCacheFs) invalidateLocked(key string) {
+	fs.gen++
+	if el, found := fs.entries[key]; found {
+		fs.removeElement(el)
+	}
+}
+
+func (fs *contentCacheFs) invalidatePrefix(name string) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.gen++
+	name = filepath.Clean(name)
+	for key, el := range fs.entries {
+		if key == name || strings.HasPrefix(key, name+filepathSeparator) {
+			fs.removeElement(el)
+		}
+	}
+}
+
+func (fs *contentCacheFs) removeElement(el *list.Element) {
+	fs.lru.Remove(el)
+	delete(fs.entries, el.Value.(*contentCacheEntry).name)
+}
+
+func newContentCacheFile(name string, e *contentCacheEntry) afero.File {
+	return newInMemoryFileWithInfo(name, e.content, e.fi)
+}
+
+// contentCacheWriteFile invalidates the cache entry for the file when closed.
+type contentCacheWriteFile struct {
+	afero.File
+	fs   *contentCacheFs
+	key  string
+	once sync.Once
+}
+
+func (f *contentCacheWriteFile) Close() error {
+	err := f.File.Close()
+	f.once.Do(func() {
+		f.fs.closeWriter(f.key)
+	})
+	return err
+}
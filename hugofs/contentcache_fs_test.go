@@ -0,0 +1,241 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+// countingOpenFs counts the calls to Open.
+type countingOpenFs struct {
+	afero.Fs
+	opens int
+}
+
+func (fs *countingOpenFs) Open(name string) (afero.File, error) {
+	fs.opens++
+	return fs.Fs.Open(name)
+}
+
+func TestContentCacheFs(t *testing.T) {
+	assert := require.New(t)
+
+	m := &countingOpenFs{Fs: afero.NewMemMapFs()}
+	afero.WriteFile(m, "small.txt", []byte("small"), 0755)
+	afero.WriteFile(m, "large.txt", []byte(strings.Repeat("a", 100)), 0755)
+	afero.WriteFile(m, "other.txt", []byte("other"), 0755)
+
+	fs := NewContentCacheFs(m, 2, 10)
+
+	read := func(name string) string {
+		b, err := afero.ReadFile(fs, name)
+		assert.NoError(err)
+		return string(b)
+	}
+
+	assert.Equal("small", read("small.txt"))
+	assert.Equal("small", read("small.txt"))
+	assert.Equal(1, m.opens)
+
+	f, err := fs.Open("small.txt")
+	assert.NoError(err)
+	fi, err := f.Stat()
+	assert.NoError(err)
+	f.Close()
+	assert.Equal("small.txt", fi.Name())
+	assert.Equal(int64(5), fi.Size())
+
+	// Too large to be cached.
+	read("large.txt")
+	read("large.txt")
+	assert.Equal(3, m.opens)
+
+	// Writes through fs invalidate the cache.
+	assert.NoError(afero.WriteFile(fs, "small.txt", []byte("edited"), 0755))
+	assert.Equal("edited", read("small.txt"))
+	assert.Equal(4, m.opens)
+	assert.Equal("edited", read("small.txt"))
+	assert.Equal(4, m.opens)
+
+	assert.NoError(fs.Remove("small.txt"))
+	_, err = fs.Open("small.txt")
+	assert.True(os.IsNotExist(err))
+
+	// Changes in the underlying filesystem needs a Reset.
+	afero.WriteFile(m, "other.txt", []byte("other"), 0755)
+	read("other.txt")
+	afero.WriteFile(m, "other.txt", []byte("changed"), 0755)
+	assert.Equal("other", read("other.txt"))
+	fs.(Reseter).Reset()
+	assert.Equal("changed", read("other.txt"))
+}
+
+func TestContentCacheFsEviction(t *testing.T) {
+	assert := require.New(t)
+
+	m := &countingOpenFs{Fs: afero.NewMemMapFs()}
+	for i := 0; i < 3; i++ {
+		afero.WriteFile(m, fmt.Sprintf("f%d.txt", i), []byte("content"), 0755)
+	}
+
+	fs := NewContentCacheFs(m, 2, 100)
+
+	for _, name := range []string{"f0.txt", "f1.txt", "f0.txt", "f2.txt"} {
+		_, err := afero.ReadFile(fs, name)
+		assert.NoError(err)
+	}
+	assert.Equal(3, m.opens)
+
+	// f1.txt was the least recently used.
+	afero.ReadFile(fs, "f0.txt")
+	assert.Equal(3, m.opens)
+	afero.ReadFile(fs, "f1.txt")
+	assert.Equal(4, m.opens)
+}
+
+func BenchmarkContentCacheFs(b *testing.B) {
+	m := afero.NewOsFs()
+	dir, err := afero.TempDir(m, "", "hugofs")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer m.RemoveAll(dir)
+
+	var filenames []string
+	for i := 0; i < 20; i++ {
+		filename := filepath.Join(dir, fmt.Sprintf("f%d.txt", i))
+		afero.WriteFile(m, filename, []byte(strings.Repeat("content ", 100)), 0755)
+		filenames = append(filenames, filename)
+	}
+
+	runBenchmark := func(b *testing.B, fs afero.Fs) {
+		for i := 0; i < b.N; i++ {
+			for _, filename := range filenames {
+				if _, err := afero.ReadFile(fs, filename); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	}
+
+	b.Run("Uncached", func(b *testing.B) {
+		runBenchmark(b, NewNoLstatFs(m))
+	})
+
+	b.Run("Cached", func(b *testing.B) {
+		runBenchmark(b, NewContentCacheFs(m, 100, 1024))
+	})
+}
+
+// racingOpenFs calls open before every Remove and Rename, as if an Open
+// raced with them.
+type racingOpenFs struct {
+	afero.Fs
+	open func(name string)
+}
+
+func (fs *racingOpenFs) Remove(name string) error {
+	fs.open(name)
+	return fs.Fs.Remove(name)
+}
+
+func (fs *racingOpenFs) Rename(oldname, newname string) error {
+	fs.open(oldname)
+	return fs.Fs.Rename(oldname, newname)
+}
+
+func TestContentCacheFsRemoveRename(t *testing.T) {
+	assert := require.New(t)
+
+	m := &racingOpenFs{Fs: afero.NewMemMapFs()}
+	afero.WriteFile(m, "removed.txt", []byte("removed"), 0755)
+	afero.WriteFile(m, "old.txt", []byte("renamed"), 0755)
+
+	fs := NewContentCacheFs(m, 10, 100)
+	m.open = func(name string) {
+		_, err := afero.ReadFile(fs, name)
+		assert.NoError(err)
+	}
+
+	assert.NoError(fs.Remove("removed.txt"))
+	_, err := fs.Open("removed.txt")
+	assert.True(os.IsNotExist(err), err)
+
+	assert.NoError(fs.Rename("old.txt", "new.txt"))
+	_, err = fs.Open("old.txt")
+	assert.True(os.IsNotExist(err), err)
+	b, err := afero.ReadFile(fs, "new.txt")
+	assert.NoError(err)
+	assert.Equal("renamed", string(b))
+}
+
+func TestContentCacheFsOpenWriter(t *testing.T) {
+	assert := require.New(t)
+
+	m := &countingOpenFs{Fs: afero.NewMemMapFs()}
+	afero.WriteFile(m, "p1.txt", []byte("v1"), 0755)
+
+	fs := NewContentCacheFs(m, 10, 100)
+
+	read := func() string {
+		b, err := afero.ReadFile(fs, "p1.txt")
+		assert.NoError(err)
+		return string(b)
+	}
+
+	assert.Equal("v1", read())
+
+	w, err := fs.OpenFile("p1.txt", os.O_WRONLY|os.O_TRUNC, 0755)
+	assert.NoError(err)
+	_, err = w.Write([]byte("v2 "))
+	assert.NoError(err)
+
+	// Not cached while open for writing.
+	assert.Equal("v2 ", read())
+	opens := m.opens
+	assert.Equal("v2 ", read())
+	assert.Equal(opens+1, m.opens)
+
+	_, err = w.Write([]byte("done"))
+	assert.NoError(err)
+	assert.NoError(w.Close())
+	assert.NoError(w.Close())
+
+	assert.Equal("v2 done", read())
+	opens = m.opens
+	assert.Equal("v2 done", read())
+	assert.Equal(opens, m.opens)
+
+	// Create.
+	w, err = fs.Create("p1.txt")
+	assert.NoError(err)
+	assert.Equal("", read())
+	_, err = w.Write([]byte("v3"))
+	assert.NoError(err)
+	assert.NoError(w.Close())
+	assert.Equal("v3", read())
+
+	lfs, ok := fs.(afero.Lstater)
+	assert.True(ok)
+	fi, _, err := lfs.LstatIfPossible("p1.txt")
+	assert.NoError(err)
+	assert.Equal(int64(2), fi.Size())
+}
@@ -0,0 +1,157 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+var (
+	_ afero.Fs = (*ContentDedupFs)(nil)
+	_ Reseter  = (*ContentDedupFs)(nil)
+)
+
+// ContentDedupFs is a filesystem that can find files with identical content,
+// e.g. vendored assets copied into several modules, so they can be processed
+// once.
+type ContentDedupFs struct {
+	afero.Fs
+
+	mu     sync.Mutex
+	hashes map[string]contentDedupEntry
+}
+
+type contentDedupEntry struct {
+	size    int64
+	modTime time.Time
+	sum     string
+}
+
+// NewContentDedupFs creates a new ContentDedupFs. Content hashes are computed
+// on demand and cached until the file's size or modification time changes,
+// or until Reset is called.
+func NewContentDedupFs(fs afero.Fs) *ContentDedupFs {
+	return &ContentDedupFs{Fs: fs, hashes: make(map[string]contentDedupEntry)}
+}
+
+// Reset clears the content hash cache.
+func (fs *ContentDedupFs) Reset() {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.hashes = make(map[string]contentDedupEntry)
+}
+
+// Name returns the name of this filesystem.
+func (fs *ContentDedupFs) Name() string {
+	return "ContentDedupFs"
+}
+
+// ContentHash returns the hex encoded MD5 hash of the named file's content.
+func (fs *ContentDedupFs) ContentHash(name string) (string, error) {
+	fi, err := fs.Fs.Stat(name)
+	if err != nil {
+		return "", err
+	}
+	return fs.contentHash(name, fi)
+}
+
+func (fs *ContentDedupFs) contentHash(filename string, fi os.FileInfo) (string, error) {
+	key := realFilename(fi, filename)
+
+	fs.mu.Lock()
+	e, found := fs.hashes[key]
+	fs.mu.Unlock()
+
+	if found && e.size == fi.Size() && e.modTime.Equal(fi.ModTime()) {
+		return e.sum, nil
+	}
+
+	sum, err := hashFile(fs.Fs, filename, md5.New())
+	if err != nil {
+		return "", err
+	}
+
+	e = contentDedupEntry{size: fi.Size(), modTime: fi.ModTime(), sum: hex.EncodeToString(sum)}
+
+	fs.mu.Lock()
+	fs.hashes[key] = e
+	fs.mu.Unlock()
+
+	return e.sum, nil
+}
+
+// DuplicateGroups walks the files below root and returns the slash separated
+// paths, relative to root, grouped by identical content. Only groups with
+// more than one path are returned. The paths in a group are sorted, and the
+// groups are sorted by their first path. Files from a language filesystem
+// have the language in the filename, e.g. "sect/page.sv.md".
+// Only files sharing their size with another file are hashed.
+func (fs *ContentDedupFs) DuplicateGroups(root string) ([][]string, error) {
+	type candidate struct {
+		filename string
+		logical  string
+		fi       os.FileInfo
+	}
+
+	bySize := make(map[int64][]candidate)
+
+	err := walkLogical(fs.Fs, root, func(filename, logical string, fi os.FileInfo) error {
+		if fi.IsDir() {
+			return nil
+		}
+		bySize[fi.Size()] = append(bySize[fi.Size()], candidate{filename: filename, logical: uniqueLogicalPath(logical, fi), fi: fi})
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	var groups [][]string
+
+	for _, candidates := range bySize {
+		if len(candidates) < 2 {
+			continue
+		}
+
+		byHash := make(map[string][]string)
+		for _, c := range candidates {
+			sum, err := fs.contentHash(c.filename, c.fi)
+			if err != nil {
+				return nil, err
+			}
+			byHash[sum] = append(byHash[sum], c.logical)
+		}
+
+		for _, paths := range byHash {
+			if len(paths) > 1 {
+				sort.Strings(paths)
+				groups = append(groups, paths)
+			}
+		}
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i][0] < groups[j][0]
+	})
+
+	return groups, nil
+}
@@ -0,0 +1,93 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContentDedupFs(t *testing.T) {
+	assert := require.New(t)
+
+	m := afero.NewMemMapFs()
+	for filename, content := range map[string]string{
+		"a/jquery.js":   "jquery",
+		"b/jquery.js":   "jquery",
+		"b/vendor.js":   "jquery",
+		"c/other.js":    "jqvery",
+		"c/logo.png":    "logo",
+		"d/logo.png":    "logo",
+		"d/unique.html": "unique",
+	} {
+		assert.NoError(afero.WriteFile(m, filepath.Join("assets", filepath.FromSlash(filename)), []byte(content), 0755))
+	}
+
+	fs := NewContentDedupFs(m)
+
+	groups, err := fs.DuplicateGroups("assets")
+	assert.NoError(err)
+	assert.Equal([][]string{
+		{"a/jquery.js", "b/jquery.js", "b/vendor.js"},
+		{"c/logo.png", "d/logo.png"},
+	}, groups)
+
+	h1, err := fs.ContentHash(filepath.FromSlash("assets/a/jquery.js"))
+	assert.NoError(err)
+	h2, err := fs.ContentHash(filepath.FromSlash("assets/b/vendor.js"))
+	assert.NoError(err)
+	assert.Equal(h1, h2)
+
+	// The cached hash is invalidated when the file changes.
+	assert.NoError(afero.WriteFile(fs, filepath.FromSlash("assets/b/vendor.js"), []byte("vendor.js"), 0755))
+	h3, err := fs.ContentHash(filepath.FromSlash("assets/b/vendor.js"))
+	assert.NoError(err)
+	assert.NotEqual(h1, h3)
+
+	groups, err = fs.DuplicateGroups("assets")
+	assert.NoError(err)
+	assert.Equal([][]string{
+		{"a/jquery.js", "b/jquery.js"},
+		{"c/logo.png", "d/logo.png"},
+	}, groups)
+
+	_, err = fs.ContentHash("nope")
+	assert.Error(err)
+}
+
+func TestContentDedupFsLanguageComposite(t *testing.T) {
+	assert := require.New(t)
+
+	languages := map[string]bool{
+		"sv": true,
+		"en": true,
+	}
+
+	m := afero.NewMemMapFs()
+	for _, lang := range []string{"sv", "en"} {
+		assert.NoError(afero.WriteFile(m, filepath.FromSlash("/content/"+lang+"/sect/logo.png"), []byte("logo"), 0755))
+	}
+
+	fs := NewContentDedupFs(NewLanguageCompositeFs(
+		NewLanguageFs("en", languages, afero.NewBasePathFs(m, filepath.FromSlash("/content/en"))),
+		NewLanguageFs("sv", languages, afero.NewBasePathFs(m, filepath.FromSlash("/content/sv"))),
+	))
+
+	groups, err := fs.DuplicateGroups("sect")
+	assert.NoError(err)
+	assert.Equal([][]string{{"logo.en.png", "logo.sv.png"}}, groups)
+}
@@ -0,0 +1,62 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+)
+
+// CopyTree copies the files below root in src into dst, using the same
+// paths relative to root. Only the files visible in src are copied, so files
+// shadowed in a composite filesystem are skipped.
+// Files from a language filesystem are written with the language in the
+// filename, e.g. "page.sv.md", so translations with the same name in
+// different content dirs don't overwrite each other and keep their language.
+func CopyTree(src afero.Fs, root string, dst afero.Fs) error {
+	return walkLogical(src, root, func(filename, logical string, fi os.FileInfo) error {
+		if fi.IsDir() {
+			return dst.MkdirAll(filepath.FromSlash(logical), 0777)
+		}
+
+		return copyFile(src, filename, dst, filepath.FromSlash(uniqueLogicalPath(logical, fi)))
+	})
+}
+
+func copyFile(src afero.Fs, from string, dst afero.Fs, to string) error {
+	in, err := src.Open(from)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if err := dst.MkdirAll(filepath.Dir(to), 0777); err != nil {
+		return err
+	}
+
+	out, err := dst.Create(to)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
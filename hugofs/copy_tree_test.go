@@ -0,0 +1,75 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func readTestTree(t testing.TB, fs afero.Fs) map[string]string {
+	files := make(map[string]string)
+	err := afero.Walk(fs, "", func(path string, fi os.FileInfo, err error) error {
+		if err != nil || fi.IsDir() {
+			return err
+		}
+		b, err := afero.ReadFile(fs, path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(path)] = string(b)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestCopyTree(t *testing.T) {
+	assert := require.New(t)
+
+	dst := afero.NewMemMapFs()
+	assert.NoError(CopyTree(newTestUnionFs(t), filepath.FromSlash("/content"), dst))
+	assert.Equal(map[string]string{
+		"about.md":   "overlay about",
+		"sect/p1.md": "overlay p1",
+		"sect/p2.md": "base p2",
+	}, readTestTree(t, dst))
+
+	languages := map[string]bool{
+		"sv": true,
+		"en": true,
+		"nn": true,
+	}
+
+	src := newTestTranslationsFs(t, languages, map[string][]string{
+		// p1.sv.md in en is shadowed by p1.md in sv.
+		"sv": {"sect/p1.md"},
+		"en": {"sect/p1.md", "sect/p1.sv.md"},
+		"nn": {"p2.md"},
+	})
+
+	dst = afero.NewMemMapFs()
+	assert.NoError(CopyTree(src, "", dst))
+	assert.Equal(map[string]string{
+		"sect/p1.sv.md": "sv sect/p1.md",
+		"sect/p1.en.md": "en sect/p1.md",
+		"p2.nn.md":      "nn p2.md",
+	}, readTestTree(t, dst))
+}
@@ -0,0 +1,88 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"os"
+	"sort"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+type treeFile struct {
+	realFilename string
+	size         int64
+	modTime      time.Time
+}
+
+func (f treeFile) equal(other treeFile) bool {
+	return f.realFilename == other.realFilename && f.size == other.size && f.modTime.Equal(other.modTime)
+}
+
+func collectTreeFiles(fs afero.Fs, root string) (map[string]treeFile, error) {
+	files := make(map[string]treeFile)
+	err := walkLogical(fs, root, func(filename, logical string, fi os.FileInfo) error {
+		if fi.IsDir() {
+			return nil
+		}
+		files[uniqueLogicalPath(logical, fi)] = treeFile{
+			realFilename: realFilename(fi, filename),
+			size:         fi.Size(),
+			modTime:      fi.ModTime(),
+		}
+		return nil
+	})
+
+	return files, err
+}
+
+// DiffTrees walks the files below root in a and b and reports the slash
+// separated paths, relative to root, that are only in b (added), only
+// in a (removed) or in both but backed by a different real file, size or
+// modification time (changed).
+// Files from a language filesystem have the language in the filename,
+// e.g. "sect/page.sv.md".
+// All three slices are sorted.
+func DiffTrees(a, b afero.Fs, root string) (added, removed, changed []string, err error) {
+	filesA, err := collectTreeFiles(a, root)
+	if err != nil {
+		return
+	}
+	filesB, err := collectTreeFiles(b, root)
+	if err != nil {
+		return
+	}
+
+	for p, fb := range filesB {
+		fa, found := filesA[p]
+		if !found {
+			added = append(added, p)
+		} else if !fa.equal(fb) {
+			changed = append(changed, p)
+		}
+	}
+
+	for p := range filesA {
+		if _, found := filesB[p]; !found {
+			removed = append(removed, p)
+		}
+	}
+
+	sort.Strings(added)
+	sort.Strings(removed)
+	sort.Strings(changed)
+
+	return
+}
@@ -0,0 +1,94 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffTrees(t *testing.T) {
+	assert := require.New(t)
+
+	modTime := time.Date(2019, time.May, 1, 0, 0, 0, 0, time.UTC)
+
+	write := func(fs afero.Fs, filename, content string) {
+		filename = filepath.FromSlash(filename)
+		assert.NoError(afero.WriteFile(fs, filename, []byte(content), 0755))
+		assert.NoError(fs.Chtimes(filename, modTime, modTime))
+	}
+
+	a := afero.NewMemMapFs()
+	write(a, "/content/same.md", "same")
+	write(a, "/content/sect/removed.md", "removed")
+	write(a, "/content/sect/changed.md", "old")
+
+	b := afero.NewMemMapFs()
+	write(b, "/content/same.md", "same")
+	write(b, "/content/sect/changed.md", "new content")
+	write(b, "/content/sect/added.md", "added")
+
+	added, removed, changed, err := DiffTrees(a, b, filepath.FromSlash("/content"))
+	assert.NoError(err)
+	assert.Equal([]string{"sect/added.md"}, added)
+	assert.Equal([]string{"sect/removed.md"}, removed)
+	assert.Equal([]string{"sect/changed.md"}, changed)
+
+	// Same logical path, but now served from another source.
+	m := afero.NewMemMapFs()
+	write(m, "/theme/content/p.md", "p")
+	write(m, "/site/content/p.md", "p")
+	theme := NewBasePathRealFilenameFs(afero.NewBasePathFs(m, filepath.FromSlash("/theme")).(*afero.BasePathFs))
+	site := NewBasePathRealFilenameFs(afero.NewBasePathFs(m, filepath.FromSlash("/site")).(*afero.BasePathFs))
+
+	added, removed, changed, err = DiffTrees(theme, site, "content")
+	assert.NoError(err)
+	assert.Empty(added)
+	assert.Empty(removed)
+	assert.Equal([]string{"p.md"}, changed)
+}
+
+func TestDiffTreesLanguageComposite(t *testing.T) {
+	assert := require.New(t)
+
+	languages := map[string]bool{
+		"sv": true,
+		"en": true,
+		"nn": true,
+	}
+
+	fs := newTestTranslationsFs(t, languages, map[string][]string{
+		"sv": {"sect/page.md"},
+		"en": {"sect/page.md", "sect/f.nn.md"},
+	})
+
+	empty := afero.NewMemMapFs()
+	assert.NoError(empty.MkdirAll("sect", 0755))
+
+	added, removed, changed, err := DiffTrees(fs, empty, "sect")
+	assert.NoError(err)
+	assert.Empty(added)
+	assert.Empty(changed)
+	assert.Equal([]string{"f.nn.md", "page.en.md", "page.sv.md"}, removed)
+
+	added, removed, changed, err = DiffTrees(empty, fs, "sect")
+	assert.NoError(err)
+	assert.Empty(removed)
+	assert.Empty(changed)
+	assert.Equal([]string{"f.nn.md", "page.en.md", "page.sv.md"}, added)
+}
@@ -0,0 +1,54 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"os"
+	"path"
+	"sort"
+
+	"github.com/spf13/afero"
+)
+
+// EmptyDirs returns the sorted, slash separated paths relative to root of
+// the directories below root without any entries. A directory in a composite
+// filesystem is only empty if it is empty in all of the layers.
+func EmptyDirs(fs afero.Fs, root string) ([]string, error) {
+	counts := make(map[string]int)
+
+	err := walkLogical(fs, root, func(filename, logical string, fi os.FileInfo) error {
+		if fi.IsDir() {
+			// Directories are visited before their entries.
+			counts[logical] = 0
+		}
+		if dir := path.Dir(logical); dir != "." {
+			counts[dir]++
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	var empty []string
+	for dir, count := range counts {
+		if count == 0 {
+			empty = append(empty, dir)
+		}
+	}
+	sort.Strings(empty)
+
+	return empty, nil
+}
@@ -0,0 +1,41 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmptyDirs(t *testing.T) {
+	assert := require.New(t)
+
+	base := afero.NewMemMapFs()
+	overlay := afero.NewMemMapFs()
+	fs := afero.NewCopyOnWriteFs(base, overlay)
+
+	for _, dir := range []string{"/content/empty", "/content/sect/empty", "/content/partly"} {
+		assert.NoError(base.MkdirAll(filepath.FromSlash(dir), 0755))
+	}
+	assert.NoError(overlay.MkdirAll(filepath.FromSlash("/content/empty"), 0755))
+	afero.WriteFile(base, filepath.FromSlash("/content/sect/p1.md"), []byte("p1"), 0755)
+	afero.WriteFile(overlay, filepath.FromSlash("/content/partly/p2.md"), []byte("p2"), 0755)
+
+	empty, err := EmptyDirs(fs, filepath.FromSlash("/content"))
+	assert.NoError(err)
+	assert.Equal([]string{"empty", "sect/empty"}, empty)
+}
@@ -0,0 +1,160 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+var (
+	_ afero.Fs      = (*extAllowlistFs)(nil)
+	_ afero.Lstater = (*extAllowlistFs)(nil)
+)
+
+type extAllowlistFs struct {
+	afero.Fs
+	allowed map[string]bool
+}
+
+// NewExtAllowlistFs creates a new filesystem that only exposes regular files
+// with one of the allowed extensions, e.g. "md" or ".md" (case insensitive).
+// Other files are hidden from Readdir and Open and Stat return os.ErrNotExist.
+// Opening such files for writing or creating them returns os.ErrPermission.
+// Directories are always allowed.
+func NewExtAllowlistFs(fs afero.Fs, allowed ...string) afero.Fs {
+	m := make(map[string]bool)
+	for _, ext := range allowed {
+		m[strings.ToLower(strings.TrimPrefix(ext, "."))] = true
+	}
+	return &extAllowlistFs{Fs: fs, allowed: m}
+}
+
+func (fs *extAllowlistFs) isAllowed(fi os.FileInfo) bool {
+	if fi.IsDir() {
+		return true
+	}
+	ext := strings.TrimPrefix(filepath.Ext(realName(fi)), ".")
+	return fs.allowed[strings.ToLower(ext)]
+}
+
+func (fs *extAllowlistFs) Stat(name string) (os.FileInfo, error) {
+	fi, err := fs.Fs.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	if !fs.isAllowed(fi) {
+		return nil, &os.PathError{Op: "stat", Path: name, Err: os.ErrNotExist}
+	}
+	return fi, nil
+}
+
+func (fs *extAllowlistFs) LstatIfPossible(name string) (os.FileInfo, bool, error) {
+	var (
+		fi  os.FileInfo
+		b   bool
+		err error
+	)
+
+	if lfs, ok := fs.Fs.(afero.Lstater); ok {
+		fi, b, err = lfs.LstatIfPossible(name)
+	} else {
+		fi, err = fs.Fs.Stat(name)
+	}
+
+	if err != nil {
+		return nil, b, err
+	}
+	if !fs.isAllowed(fi) {
+		return nil, b, &os.PathError{Op: "lstat", Path: name, Err: os.ErrNotExist}
+	}
+
+	return fi, b, nil
+}
+
+func (fs *extAllowlistFs) Open(name string) (afero.File, error) {
+	f, err := fs.Fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	if !fs.isAllowed(fi) {
+		f.Close()
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+
+	if fi.IsDir() {
+		return &filteringDir{File: f, filter: func(fi os.FileInfo) os.FileInfo {
+			if fs.isAllowed(fi) {
+				return fi
+			}
+			return nil
+		}}, nil
+	}
+
+	return f, nil
+}
+
+func (fs *extAllowlistFs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	if !isWrite(flag) && flag&os.O_CREATE == 0 {
+		return fs.Open(name)
+	}
+
+	// Check before delegating, as opening for write may truncate or create the file.
+	if err := fs.checkWrite("open", name); err != nil {
+		return nil, err
+	}
+
+	return fs.Fs.OpenFile(name, flag, perm)
+}
+
+func (fs *extAllowlistFs) Create(name string) (afero.File, error) {
+	if err := fs.checkWrite("create", name); err != nil {
+		return nil, err
+	}
+	return fs.Fs.Create(name)
+}
+
+// checkWrite returns an error if name is, or would be created as, a regular
+// file with an extension that is not allowed.
+func (fs *extAllowlistFs) checkWrite(op, name string) error {
+	fi, err := fs.Fs.Stat(name)
+	if err == nil {
+		if fs.isAllowed(fi) {
+			return nil
+		}
+	} else if os.IsNotExist(err) {
+		ext := strings.TrimPrefix(filepath.Ext(name), ".")
+		if fs.allowed[strings.ToLower(ext)] {
+			return nil
+		}
+	} else {
+		return err
+	}
+
+	return &os.PathError{Op: op, Path: name, Err: os.ErrPermission}
+}
+
+func (fs *extAllowlistFs) Name() string {
+	return "extAllowlistFs"
+}
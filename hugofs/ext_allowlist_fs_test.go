@@ -0,0 +1,139 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtAllowlistFs(t *testing.T) {
+	assert := require.New(t)
+
+	m := afero.NewMemMapFs()
+	for _, filename := range []string{"p1.md", "p2.MD", "sect/p3.md", "sect/run.exe", "archive.zip", "dir.zip/p4.md"} {
+		assert.NoError(afero.WriteFile(m, filepath.Join("content", filepath.FromSlash(filename)), []byte(filename), 0755))
+	}
+
+	fs := NewExtAllowlistFs(m, "md", ".html")
+
+	readdirnames := func(dir string) []string {
+		f, err := fs.Open(filepath.FromSlash(dir))
+		assert.NoError(err)
+		defer f.Close()
+		names, err := f.Readdirnames(-1)
+		assert.NoError(err)
+		return names
+	}
+
+	assert.Equal([]string{"dir.zip", "p1.md", "p2.MD", "sect"}, readdirnames("content"))
+	assert.Equal([]string{"p3.md"}, readdirnames("content/sect"))
+	assert.Equal([]string{"p4.md"}, readdirnames("content/dir.zip"))
+
+	fis, err := afero.ReadDir(fs, "content")
+	assert.NoError(err)
+	assert.Len(fis, 4)
+
+	for _, filename := range []string{"content/sect/run.exe", "content/archive.zip"} {
+		filename = filepath.FromSlash(filename)
+		_, err := fs.Open(filename)
+		assert.True(os.IsNotExist(err), filename)
+		_, err = fs.Stat(filename)
+		assert.True(os.IsNotExist(err), filename)
+		_, _, err = fs.(afero.Lstater).LstatIfPossible(filename)
+		assert.True(os.IsNotExist(err), filename)
+	}
+
+	b, err := afero.ReadFile(fs, filepath.FromSlash("content/sect/p3.md"))
+	assert.NoError(err)
+	assert.Equal("sect/p3.md", string(b))
+
+	// Read in chunks.
+	f, err := fs.Open(filepath.FromSlash("content/sect"))
+	assert.NoError(err)
+	defer f.Close()
+	fis, err = f.Readdir(1)
+	assert.NoError(err)
+	assert.Len(fis, 1)
+	assert.Equal("p3.md", fis[0].Name())
+}
+
+func TestExtAllowlistFsWrite(t *testing.T) {
+	assert := require.New(t)
+
+	m := afero.NewMemMapFs()
+	for _, filename := range []string{"p1.md", "run.sh"} {
+		assert.NoError(afero.WriteFile(m, filename, []byte(filename), 0755))
+	}
+
+	fs := NewExtAllowlistFs(m, "md")
+
+	for _, test := range []struct {
+		name string
+		flag int
+	}{
+		{"run.sh", os.O_RDWR | os.O_TRUNC},
+		{"run.sh", os.O_WRONLY},
+		{"new.exe", os.O_CREATE | os.O_WRONLY},
+	} {
+		_, err := fs.OpenFile(test.name, test.flag, 0755)
+		assert.True(os.IsPermission(err), test.name)
+	}
+
+	_, err := fs.Create("new.exe")
+	assert.True(os.IsPermission(err))
+
+	// Nothing was truncated or created.
+	b, err := afero.ReadFile(m, "run.sh")
+	assert.NoError(err)
+	assert.Equal("run.sh", string(b))
+	_, err = m.Stat("new.exe")
+	assert.True(os.IsNotExist(err))
+
+	f, err := fs.OpenFile("p1.md", os.O_WRONLY|os.O_TRUNC, 0755)
+	assert.NoError(err)
+	f.Close()
+	f, err = fs.Create("p2.md")
+	assert.NoError(err)
+	f.Close()
+}
+
+func TestExtAllowlistFsLanguageComposite(t *testing.T) {
+	assert := require.New(t)
+
+	languages := map[string]bool{
+		"sv": true,
+		"en": true,
+		"nn": true,
+	}
+
+	composite := newTestTranslationsFs(t, languages, map[string][]string{
+		"sv": {"sect/page.md", "sect/run.sh"},
+		"en": {"sect/page.md", "sect/f.nn.md"},
+	})
+
+	fs := NewExtAllowlistFs(composite, "md")
+
+	fis, err := afero.ReadDir(fs, "sect")
+	assert.NoError(err)
+	assert.Len(fis, 3)
+	for _, fi := range fis {
+		_, ok := fi.(*LanguageFileInfo)
+		assert.True(ok, fi.Name())
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+var (
+	_ afero.Fs      = (*fallbackFs)(nil)
+	_ afero.Lstater = (*fallbackFs)(nil)
+)
+
+// NewFallbackFs creates a new filesystem that consults fallback when a file
+// opened for reading or a Stat does not exist. If fallback returns ok, a
+// read-only, virtual file with the returned content is served instead.
+// This is meant for preview servers, e.g. to serve a placeholder for a
+// missing image. A nil fallback disables this.
+func NewFallbackFs(fs afero.Fs, fallback func(name string) ([]byte, bool)) afero.Fs {
+	return &fallbackFs{Fs: fs, fallback: fallback}
+}
+
+type fallbackFs struct {
+	afero.Fs
+	fallback func(name string) ([]byte, bool)
+}
+
+func (fs *fallbackFs) Stat(name string) (os.FileInfo, error) {
+	fi, err := fs.Fs.Stat(name)
+	if err != nil {
+		if content, ok := fs.lookup(name, err); ok {
+			return fs.newFileInfo(name, content), nil
+		}
+		return nil, err
+	}
+	return fi, nil
+}
+
+func (fs *fallbackFs) LstatIfPossible(name string) (os.FileInfo, bool, error) {
+	var (
+		fi  os.FileInfo
+		b   bool
+		err error
+	)
+
+	if lfs, ok := fs.Fs.(afero.Lstater); ok {
+		fi, b, err = lfs.LstatIfPossible(name)
+	} else {
+		fi, err = fs.Fs.Stat(name)
+	}
+
+	if err != nil {
+		if content, ok := fs.lookup(name, err); ok {
+			return fs.newFileInfo(name, content), false, nil
+		}
+		return nil, b, err
+	}
+
+	return fi, b, nil
+}
+
+func (fs *fallbackFs) Open(name string) (afero.File, error) {
+	f, err := fs.Fs.Open(name)
+	if err != nil {
+		if content, ok := fs.lookup(name, err); ok {
+			return newInMemoryFile(name, content), nil
+		}
+		return nil, err
+	}
+	return f, nil
+}
+
+func (fs *fallbackFs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	if !isWrite(flag) && flag&os.O_CREATE == 0 {
+		return fs.Open(name)
+	}
+	return fs.Fs.OpenFile(name, flag, perm)
+}
+
+func (fs *fallbackFs) Name() string {
+	return "fallbackFs"
+}
+
+func (fs *fallbackFs) lookup(name string, err error) ([]byte, bool) {
+	if fs.fallback == nil || !os.IsNotExist(err) {
+		return nil, false
+	}
+	return fs.fallback(name)
+}
+
+func (fs *fallbackFs) newFileInfo(name string, content []byte) os.FileInfo {
+	return &inMemoryFileInfo{name: filepath.Base(name), size: int64(len(content)), modTime: time.Now()}
+}
@@ -0,0 +1,85 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFallbackFs(t *testing.T) {
+	assert := require.New(t)
+
+	m := afero.NewMemMapFs()
+	assert.NoError(afero.WriteFile(m, filepath.FromSlash("static/logo.png"), []byte("logo"), 0755))
+
+	var lookups []string
+	fallback := func(name string) ([]byte, bool) {
+		lookups = append(lookups, name)
+		if strings.HasSuffix(name, ".png") {
+			return []byte("placeholder"), true
+		}
+		return nil, false
+	}
+
+	fs := NewFallbackFs(m, fallback)
+
+	// Present files are not affected.
+	b, err := afero.ReadFile(fs, filepath.FromSlash("static/logo.png"))
+	assert.NoError(err)
+	assert.Equal("logo", string(b))
+	fi, err := fs.Stat(filepath.FromSlash("static/logo.png"))
+	assert.NoError(err)
+	assert.Equal(int64(4), fi.Size())
+	assert.Empty(lookups)
+
+	// Missing with fallback.
+	missing := filepath.FromSlash("static/missing.png")
+	b, err = afero.ReadFile(fs, missing)
+	assert.NoError(err)
+	assert.Equal("placeholder", string(b))
+	fi, err = fs.Stat(missing)
+	assert.NoError(err)
+	assert.Equal("missing.png", fi.Name())
+	assert.Equal(int64(len("placeholder")), fi.Size())
+	assert.False(fi.IsDir())
+	fi, _, err = fs.(afero.Lstater).LstatIfPossible(missing)
+	assert.NoError(err)
+	assert.Equal("missing.png", fi.Name())
+
+	// The placeholder is read-only.
+	f, err := fs.Open(missing)
+	assert.NoError(err)
+	_, err = f.Write([]byte("a"))
+	assert.Error(err)
+	f.Close()
+
+	// Missing without fallback content.
+	_, err = fs.Open(filepath.FromSlash("static/missing.css"))
+	assert.True(os.IsNotExist(err))
+	_, err = fs.Stat(filepath.FromSlash("static/missing.css"))
+	assert.True(os.IsNotExist(err))
+
+	// Nil fallback.
+	fs = NewFallbackFs(m, nil)
+	_, err = fs.Open(missing)
+	assert.True(os.IsNotExist(err))
+	_, err = fs.Stat(missing)
+	assert.True(os.IsNotExist(err))
+}
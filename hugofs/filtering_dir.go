@@ -0,0 +1,58 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"os"
+
+	"github.com/spf13/afero"
+)
+
+// filteringDir is a directory that passes every entry in Readdir and
+// Readdirnames through filter. If filter returns nil, the entry is dropped,
+// else it is replaced with the returned value.
+type filteringDir struct {
+	afero.File
+	filter func(fi os.FileInfo) os.FileInfo
+}
+
+func (d *filteringDir) Readdir(count int) ([]os.FileInfo, error) {
+	for {
+		fis, err := d.File.Readdir(count)
+
+		n := 0
+		for _, fi := range fis {
+			if fi = d.filter(fi); fi != nil {
+				fis[n] = fi
+				n++
+			}
+		}
+		fis = fis[:n]
+
+		// Make sure we don't return an empty slice without an error
+		// when reading in chunks.
+		if err != nil || count <= 0 || len(fis) > 0 {
+			return fis, err
+		}
+	}
+}
+
+func (d *filteringDir) Readdirnames(count int) ([]string, error) {
+	fis, err := d.Readdir(count)
+	names := make([]string, len(fis))
+	for i, fi := range fis {
+		names[i] = fi.Name()
+	}
+	return names, err
+}
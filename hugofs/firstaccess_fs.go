@@ -0,0 +1,95 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/spf13/afero"
+)
+
+var _ afero.Fs = (*firstAccessLogFs)(nil)
+
+// NewFirstAccessLogFs creates a new filesystem that records the real filename
+// of every distinct path opened, in the order they were first opened.
+// The returned func returns a copy of the recorded filenames.
+// This can be used to find out which files, e.g. in a theme, are actually used.
+func NewFirstAccessLogFs(fs afero.Fs) (afero.Fs, func() []string) {
+	afs := &firstAccessLogFs{Fs: fs, seen: make(map[string]bool)}
+	return afs, afs.accessed
+}
+
+type firstAccessLogFs struct {
+	afero.Fs
+
+	mu        sync.Mutex
+	seen      map[string]bool
+	filenames []string
+}
+
+func (fs *firstAccessLogFs) Open(name string) (afero.File, error) {
+	f, err := fs.Fs.Open(name)
+	if err == nil {
+		fs.onOpen(name)
+	}
+	return f, err
+}
+
+func (fs *firstAccessLogFs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	f, err := fs.Fs.OpenFile(name, flag, perm)
+	if err == nil {
+		fs.onOpen(name)
+	}
+	return f, err
+}
+
+func (fs *firstAccessLogFs) Name() string {
+	return "firstAccessLogFs"
+}
+
+func (fs *firstAccessLogFs) onOpen(name string) {
+	name = filepath.Clean(name)
+
+	// Reserve the slot under the lock to keep the order, but do the Stat
+	// outside of it, as it can be slow in the underlying filesystem.
+	fs.mu.Lock()
+	if fs.seen[name] {
+		fs.mu.Unlock()
+		return
+	}
+	fs.seen[name] = true
+	i := len(fs.filenames)
+	fs.filenames = append(fs.filenames, name)
+	fs.mu.Unlock()
+
+	fi, err := fs.Fs.Stat(name)
+	if err != nil {
+		return
+	}
+
+	fs.mu.Lock()
+	fs.filenames[i] = realFilename(fi, name)
+	fs.mu.Unlock()
+}
+
+func (fs *firstAccessLogFs) accessed() []string {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	filenames := make([]string, len(fs.filenames))
+	copy(filenames, fs.filenames)
+	return filenames
+}
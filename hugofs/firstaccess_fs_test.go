@@ -0,0 +1,66 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFirstAccessLogFs(t *testing.T) {
+	assert := require.New(t)
+
+	m := afero.NewMemMapFs()
+	var expected []string
+	for i := 0; i < 10; i++ {
+		filename := filepath.FromSlash(fmt.Sprintf("/theme/layouts/f%d.html", i))
+		afero.WriteFile(m, filename, []byte("content"), 0755)
+		expected = append(expected, filename)
+	}
+
+	fs, accessed := NewFirstAccessLogFs(NewBasePathRealFilenameFs(afero.NewBasePathFs(m, filepath.FromSlash("/theme")).(*afero.BasePathFs)))
+
+	afero.ReadFile(fs, filepath.FromSlash("layouts/f3.html"))
+	afero.ReadFile(fs, filepath.FromSlash("layouts/f1.html"))
+	afero.ReadFile(fs, filepath.FromSlash("layouts/f3.html"))
+	_, err := fs.Open("nope.html")
+	assert.Error(err)
+
+	assert.Equal([]string{expected[3], expected[1]}, accessed())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				if _, err := afero.ReadFile(fs, filepath.FromSlash(fmt.Sprintf("layouts/f%d.html", j))); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	got := accessed()
+	assert.Equal([]string{expected[3], expected[1]}, got[:2])
+	sort.Strings(got)
+	assert.Equal(expected, got)
+}
@@ -0,0 +1,119 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"os"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+var (
+	_ afero.Fs      = (*fixedModTimeFs)(nil)
+	_ afero.Lstater = (*fixedModTimeFs)(nil)
+)
+
+// NewFixedModTimeFs creates a new filesystem where every os.FileInfo returned
+// from Stat, LstatIfPossible and Readdir reports modTime as its modification
+// time. This is useful for reproducible builds.
+// LanguageFileInfo and RealFilenameInfo values keep their type.
+func NewFixedModTimeFs(fs afero.Fs, modTime time.Time) afero.Fs {
+	return &fixedModTimeFs{Fs: fs, modTime: modTime}
+}
+
+type fixedModTimeFs struct {
+	afero.Fs
+	modTime time.Time
+}
+
+func (fs *fixedModTimeFs) Stat(name string) (os.FileInfo, error) {
+	fi, err := fs.Fs.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	return fs.fix(fi), nil
+}
+
+func (fs *fixedModTimeFs) LstatIfPossible(name string) (os.FileInfo, bool, error) {
+	if lfs, ok := fs.Fs.(afero.Lstater); ok {
+		fi, b, err := lfs.LstatIfPossible(name)
+		if err != nil {
+			return nil, b, err
+		}
+		return fs.fix(fi), b, nil
+	}
+	fi, err := fs.Stat(name)
+	return fi, false, err
+}
+
+func (fs *fixedModTimeFs) Open(name string) (afero.File, error) {
+	f, err := fs.Fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return fs.wrapFile(f), nil
+}
+
+func (fs *fixedModTimeFs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	f, err := fs.Fs.OpenFile(name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	return fs.wrapFile(f), nil
+}
+
+func (fs *fixedModTimeFs) Name() string {
+	return "fixedModTimeFs"
+}
+
+func (fs *fixedModTimeFs) wrapFile(f afero.File) afero.File {
+	return &fixedModTimeFile{filteringDir: &filteringDir{File: f, filter: fs.fix}, fs: fs}
+}
+
+func (fs *fixedModTimeFs) fix(fi os.FileInfo) os.FileInfo {
+	switch v := fi.(type) {
+	case *LanguageFileInfo:
+		c := *v
+		c.FileInfo = fs.fix(v.FileInfo)
+		return &c
+	case *realFilenameInfo:
+		c := *v
+		c.FileInfo = fs.fix(v.FileInfo)
+		return &c
+	}
+	return &fixedModTimeFileInfo{FileInfo: fi, modTime: fs.modTime}
+}
+
+type fixedModTimeFile struct {
+	*filteringDir
+	fs *fixedModTimeFs
+}
+
+func (f *fixedModTimeFile) Stat() (os.FileInfo, error) {
+	fi, err := f.File.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return f.fs.fix(fi), nil
+}
+
+type fixedModTimeFileInfo struct {
+	os.FileInfo
+	modTime time.Time
+}
+
+func (fi *fixedModTimeFileInfo) ModTime() time.Time {
+	return fi.modTime
+}
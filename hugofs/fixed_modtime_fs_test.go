@@ -0,0 +1,81 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFixedModTimeFs(t *testing.T) {
+	assert := require.New(t)
+
+	modTime := time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	languages := map[string]bool{
+		"sv": true,
+		"en": true,
+		"nn": true,
+	}
+
+	fs := NewFixedModTimeFs(newTestTranslationsFs(t, languages, map[string][]string{
+		"sv": {"sect/p1.md"},
+		"en": {"sect/p1.md", "sect/p2.md"},
+		"nn": {"p3.md"},
+	}), modTime)
+
+	count := 0
+	err := afero.Walk(fs, "/", func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		assert.True(fi.ModTime().Equal(modTime), path)
+		if !fi.IsDir() {
+			count++
+			lfi, ok := fi.(*LanguageFileInfo)
+			assert.True(ok)
+			assert.NotEmpty(lfi.Lang())
+
+			b, err := afero.ReadFile(fs, path)
+			assert.NoError(err)
+			assert.Equal(lfi.Lang()+" "+filepath.ToSlash(lfi.Path()), string(b))
+
+			f, err := fs.Open(path)
+			assert.NoError(err)
+			sfi, err := f.Stat()
+			f.Close()
+			assert.NoError(err)
+			assert.True(sfi.ModTime().Equal(modTime), path)
+		}
+		return nil
+	})
+
+	assert.NoError(err)
+	assert.Equal(4, count)
+
+	m := afero.NewMemMapFs()
+	afero.WriteFile(m, filepath.FromSlash("/base/f.txt"), []byte("f"), 0755)
+	rfs := NewFixedModTimeFs(NewBasePathRealFilenameFs(afero.NewBasePathFs(m, "/base").(*afero.BasePathFs)), modTime)
+	fi, err := rfs.Stat("f.txt")
+	assert.NoError(err)
+	assert.True(fi.ModTime().Equal(modTime))
+	rfi, ok := fi.(RealFilenameInfo)
+	assert.True(ok)
+	assert.Equal(filepath.FromSlash("/base/f.txt"), rfi.RealFilename())
+}
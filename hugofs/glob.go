@@ -0,0 +1,87 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// GlobDirs returns the files with a slash separated path matching pattern,
+// as defined by path.Match, grouped by their parent directory, e.g.
+// "layouts/_default/*.html". The paths are matched in their original form,
+// e.g. "sect/page.md" and not "sect/__hugofs_sv_page.md", so the files
+// from a language composite filesystem are matched after the merge.
+// Directories without any match are not included in the result, and the
+// files in a directory are in the order returned by Readdir.
+func GlobDirs(fs afero.Fs, pattern string) (map[string][]os.FileInfo, error) {
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+
+	root := globRoot(pattern)
+	matches := make(map[string][]os.FileInfo)
+
+	if root != "" {
+		if _, err := fs.Stat(filepath.FromSlash(root)); err != nil {
+			if os.IsNotExist(err) {
+				return matches, nil
+			}
+			return nil, err
+		}
+	}
+
+	err := walkLogical(fs, filepath.FromSlash(root), func(filename, logical string, fi os.FileInfo) error {
+		if fi.IsDir() {
+			return nil
+		}
+
+		logical = path.Join(root, logical)
+
+		if ok, _ := path.Match(pattern, logical); ok {
+			dir := path.Dir(logical)
+			matches[dir] = append(matches[dir], fi)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return matches, nil
+}
+
+// globRoot returns the leading directories of pattern without any special
+// characters, which is where the walk can start.
+func globRoot(pattern string) string {
+	parts := strings.Split(pattern, "/")
+	parts = parts[:len(parts)-1]
+
+	for i, part := range parts {
+		if strings.ContainsAny(part, `*?[\`) {
+			parts = parts[:i]
+			break
+		}
+	}
+
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,103 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGlobDirs(t *testing.T) {
+	assert := require.New(t)
+
+	m := afero.NewMemMapFs()
+	for _, filename := range []string{
+		"layouts/_default/list.html",
+		"layouts/_default/single.html",
+		"layouts/_default/single.json",
+		"layouts/blog/single.html",
+		"layouts/partials/header.html",
+		"layouts/shortcodes/img.txt",
+		"static/index.html",
+	} {
+		assert.NoError(afero.WriteFile(m, filepath.FromSlash(filename), []byte(filename), 0755))
+	}
+
+	names := func(matches map[string][]os.FileInfo) map[string][]string {
+		m := make(map[string][]string)
+		for dir, fis := range matches {
+			for _, fi := range fis {
+				m[dir] = append(m[dir], realName(fi))
+			}
+		}
+		return m
+	}
+
+	matches, err := GlobDirs(m, "layouts/*/*.html")
+	assert.NoError(err)
+	assert.Equal(map[string][]string{
+		"layouts/_default": {"list.html", "single.html"},
+		"layouts/blog":     {"single.html"},
+		"layouts/partials": {"header.html"},
+	}, names(matches))
+
+	matches, err = GlobDirs(m, "/layouts/_default/single.*")
+	assert.NoError(err)
+	assert.Equal(map[string][]string{
+		"layouts/_default": {"single.html", "single.json"},
+	}, names(matches))
+
+	matches, err = GlobDirs(m, "*/index.html")
+	assert.NoError(err)
+	assert.Equal(map[string][]string{
+		"static": {"index.html"},
+	}, names(matches))
+
+	matches, err = GlobDirs(m, "layouts/nope/*")
+	assert.NoError(err)
+	assert.Empty(matches)
+
+	_, err = GlobDirs(m, "layouts/[")
+	assert.Error(err)
+}
+
+func TestGlobDirsLanguageFs(t *testing.T) {
+	assert := require.New(t)
+
+	languages := map[string]bool{
+		"sv": true,
+		"en": true,
+		"nn": true,
+	}
+
+	fs := newTestTranslationsFs(t, languages, map[string][]string{
+		"sv": {"sect/p1.md", "sect/p3.txt"},
+		"en": {"sect/p1.md", "sect/p2.md", "sect/p2.sv.md", "other/p4.md"},
+	})
+
+	matches, err := GlobDirs(fs, "sect/*.md")
+	assert.NoError(err)
+	assert.Len(matches, 1)
+
+	var langs []string
+	for _, fi := range matches["sect"] {
+		lfi := fi.(*LanguageFileInfo)
+		langs = append(langs, lfi.Lang()+":"+lfi.RealName())
+	}
+	assert.ElementsMatch([]string{"en:p1.md", "sv:p1.md", "en:p2.md", "sv:p2.sv.md"}, langs)
+}
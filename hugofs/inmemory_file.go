@@ -0,0 +1,114 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+var _ afero.File = (*inMemoryFile)(nil)
+
+// newInMemoryFile creates a read-only, in-memory file with the given name and content.
+func newInMemoryFile(name string, content []byte) afero.File {
+	fi := &inMemoryFileInfo{name: filepath.Base(name), size: int64(len(content)), modTime: time.Now()}
+	return newInMemoryFileWithInfo(name, content, fi)
+}
+
+// newInMemoryFileWithInfo creates a read-only, in-memory file with the given
+// name and content reporting fi in Stat.
+func newInMemoryFileWithInfo(name string, content []byte, fi os.FileInfo) afero.File {
+	return &inMemoryFile{Reader: bytes.NewReader(content), name: name, fi: fi}
+}
+
+type inMemoryFile struct {
+	*bytes.Reader
+	name string
+	fi   os.FileInfo
+}
+
+func (f *inMemoryFile) Close() error {
+	return nil
+}
+
+func (f *inMemoryFile) Name() string {
+	return f.name
+}
+
+func (f *inMemoryFile) Stat() (os.FileInfo, error) {
+	return f.fi, nil
+}
+
+func (f *inMemoryFile) Readdir(count int) ([]os.FileInfo, error) {
+	return nil, &os.PathError{Op: "readdir", Path: f.name, Err: syscall.ENOTDIR}
+}
+
+func (f *inMemoryFile) Readdirnames(n int) ([]string, error) {
+	return nil, &os.PathError{Op: "readdir", Path: f.name, Err: syscall.ENOTDIR}
+}
+
+func (f *inMemoryFile) Sync() error {
+	return nil
+}
+
+func (f *inMemoryFile) Truncate(size int64) error {
+	return &os.PathError{Op: "truncate", Path: f.name, Err: syscall.EPERM}
+}
+
+func (f *inMemoryFile) Write(p []byte) (int, error) {
+	return 0, &os.PathError{Op: "write", Path: f.name, Err: syscall.EPERM}
+}
+
+func (f *inMemoryFile) WriteAt(p []byte, off int64) (int, error) {
+	return 0, &os.PathError{Op: "write", Path: f.name, Err: syscall.EPERM}
+}
+
+func (f *inMemoryFile) WriteString(s string) (int, error) {
+	return 0, &os.PathError{Op: "write", Path: f.name, Err: syscall.EPERM}
+}
+
+type inMemoryFileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+func (fi *inMemoryFileInfo) Name() string {
+	return fi.name
+}
+
+func (fi *inMemoryFileInfo) Size() int64 {
+	return fi.size
+}
+
+func (fi *inMemoryFileInfo) Mode() os.FileMode {
+	return 0444
+}
+
+func (fi *inMemoryFileInfo) ModTime() time.Time {
+	return fi.modTime
+}
+
+func (fi *inMemoryFileInfo) IsDir() bool {
+	return false
+}
+
+func (fi *inMemoryFileInfo) Sys() interface{} {
+	return nil
+}
@@ -0,0 +1,80 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.17
+// +build go1.17
+
+package hugofs
+
+import (
+	iofs "io/fs"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+)
+
+var (
+	_ iofs.FS          = (*ioFS)(nil)
+	_ iofs.ReadDirFile = (*ioFile)(nil)
+)
+
+// AsIOFS adapts fs to the io/fs.FS interface. Directory reads go through fs,
+// so the entries are merged as usual, and the os.FileInfo returned from
+// fs.DirEntry.Info is the one from fs, e.g. a *LanguageFileInfo.
+// Note that the entry names are the names from fs, which for a language
+// filesystem are the marked names, e.g. "__hugofs_sv_page.md", as the real
+// names are not unique in a merged view. fs.WalkDir and fs.Glob therefore
+// report paths such as "sect/__hugofs_sv_page.md", which can be opened, but
+// must not be matched on the real file name. Use LanguageFileInfo.RealName
+// on the entry's Info for that.
+func AsIOFS(fs afero.Fs) iofs.FS {
+	return &ioFS{fs: fs}
+}
+
+type ioFS struct {
+	fs afero.Fs
+}
+
+func (fs *ioFS) Open(name string) (iofs.File, error) {
+	if !iofs.ValidPath(name) {
+		return nil, &iofs.PathError{Op: "open", Path: name, Err: iofs.ErrInvalid}
+	}
+
+	if name == "." {
+		name = ""
+	}
+
+	f, err := fs.fs.Open(filepath.FromSlash(name))
+	if err != nil {
+		return nil, err
+	}
+
+	return &ioFile{File: f}, nil
+}
+
+type ioFile struct {
+	afero.File
+}
+
+func (f *ioFile) Stat() (iofs.FileInfo, error) {
+	return f.File.Stat()
+}
+
+func (f *ioFile) ReadDir(count int) ([]iofs.DirEntry, error) {
+	fis, err := f.File.Readdir(count)
+	entries := make([]iofs.DirEntry, len(fis))
+	for i, fi := range fis {
+		entries[i] = iofs.FileInfoToDirEntry(fi)
+	}
+	return entries, err
+}
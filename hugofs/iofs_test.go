@@ -0,0 +1,97 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.17
+// +build go1.17
+
+package hugofs
+
+import (
+	iofs "io/fs"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAsIOFS(t *testing.T) {
+	assert := require.New(t)
+
+	languages := map[string]bool{
+		"sv": true,
+		"en": true,
+		"nn": true,
+	}
+
+	fs := AsIOFS(newTestTranslationsFs(t, languages, map[string][]string{
+		"sv": {"sect/p1.md"},
+		"en": {"sect/p1.md", "sect/p1.sv.md", "sect/p2.md"},
+		"nn": {"p3.md"},
+	}))
+
+	var got, paths []string
+
+	err := iofs.WalkDir(fs, ".", func(path string, d iofs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		lfi, ok := info.(*LanguageFileInfo)
+		assert.True(ok, path)
+		got = append(got, lfi.Lang()+":"+filepath.ToSlash(lfi.Filename()))
+		paths = append(paths, path)
+		assert.Equal(lfi.Name(), d.Name())
+
+		b, err := iofs.ReadFile(fs, path)
+		assert.NoError(err)
+		assert.Equal(lfi.Lang()+" "+filepath.ToSlash(lfi.Path()), string(b))
+
+		return nil
+	})
+
+	assert.NoError(err)
+
+	sort.Strings(got)
+
+	// sect/p1.sv.md in en loses against sect/p1.md in sv.
+	assert.Equal([]string{
+		"en:/content/en/sect/p1.md",
+		"en:/content/en/sect/p2.md",
+		"nn:/content/nn/p3.md",
+		"sv:/content/sv/sect/p1.md",
+	}, got)
+
+	// The paths have the marked names from the language filesystems.
+	sort.Strings(paths)
+	assert.Equal([]string{
+		"__hugofs_nn_p3.md",
+		"sect/__hugofs_en_p1.md",
+		"sect/__hugofs_en_p2.md",
+		"sect/__hugofs_sv_p1.md",
+	}, paths)
+
+	matches, err := iofs.Glob(fs, "sect/*p1.md")
+	assert.NoError(err)
+	assert.Equal([]string{"sect/__hugofs_en_p1.md", "sect/__hugofs_sv_p1.md"}, matches)
+
+	_, err = fs.Open("../p3.md")
+	assert.Error(err)
+}
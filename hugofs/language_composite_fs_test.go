@@ -105,3 +105,245 @@ func assertLangFile(t testing.TB, fs afero.Fs, filename, match string) {
 
 	}
 }
+
+func TestCompositeLanguagFsEqualWeights(t *testing.T) {
+	assert := require.New(t)
+
+	languages := map[string]bool{
+		"sv": true,
+		"en": true,
+		"nn": true,
+	}
+
+	// f.nn.md has weight 1 in both the sv and the en filesystem.
+	fs := newTestTranslationsFs(t, languages, map[string][]string{
+		"sv": {"f.nn.md"},
+		"en": {"f.nn.md"},
+	})
+
+	for i := 0; i < 10; i++ {
+		fis, err := afero.ReadDir(fs, "/")
+		assert.NoError(err)
+		assert.Len(fis, 1)
+		lfi := fis[0].(*LanguageFileInfo)
+		assert.Equal("nn", lfi.Lang())
+		assert.Equal(filepath.FromSlash("/content/sv/f.nn.md"), lfi.Filename())
+	}
+}
+
+func TestCompositeLanguageFsInfixPolicy(t *testing.T) {
+	languages := map[string]bool{
+		"en": true,
+		"nn": true,
+	}
+
+	for _, test := range []struct {
+		name     string
+		opts     []LanguageFsOption
+		expected string
+	}{
+		{"default", nil, "post.en.md"},
+		{"infix", []LanguageFsOption{WithInfixPolicy(InfixFileWins)}, "post.en.md"},
+		{"bare", []LanguageFsOption{WithInfixPolicy(BareFileWins)}, "post.md"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			assert := require.New(t)
+
+			m := afero.NewMemMapFs()
+			for _, filename := range []string{"/content/en/sect/post.md", "/content/en/sect/post.en.md", "/content/nn/sect/post.md"} {
+				assert.NoError(afero.WriteFile(m, filepath.FromSlash(filename), []byte(filename), 0755))
+			}
+
+			newFs := func(lang string, opts ...LanguageFsOption) *LanguageFs {
+				return NewLanguageFs(lang, languages, afero.NewBasePathFs(m, filepath.FromSlash("/content/"+lang)), opts...)
+			}
+
+			// The en files in both the overlay and the base layer.
+			for _, composite := range []afero.Fs{
+				NewLanguageCompositeFs(newFs("nn"), newFs("en", test.opts...)),
+				NewLanguageCompositeFs(newFs("en", test.opts...), newFs("nn")),
+			} {
+				fis, err := afero.ReadDir(composite, "sect")
+				assert.NoError(err)
+				assert.Len(fis, 2)
+
+				var en *LanguageFileInfo
+				for _, fi := range fis {
+					if lfi := fi.(*LanguageFileInfo); lfi.Lang() == "en" {
+						en = lfi
+					}
+				}
+				assert.NotNil(en)
+				assert.Equal(test.expected, en.RealName())
+			}
+
+			paths, err := TranslationPaths(newFs("en", test.opts...), "sect", "post")
+			assert.NoError(err)
+			assert.Equal(filepath.FromSlash("/content/en/sect/"+test.expected), paths["en"])
+		})
+	}
+}
+
+func TestCompositeLanguageFsTranslationInfo(t *testing.T) {
+	assert := require.New(t)
+
+	languages := map[string]bool{
+		"sv": true,
+		"en": true,
+		"de": true,
+	}
+
+	// A double underscore delimits the language, e.g. "post__de.md".
+	translationInfo := func(name string) (string, string) {
+		base := strings.TrimSuffix(name, filepath.Ext(name))
+		i := strings.LastIndex(base, "__")
+		if i < 0 {
+			return "", base
+		}
+		return base[i+2:], base[:i]
+	}
+
+	m := afero.NewMemMapFs()
+	for _, filename := range []string{
+		"/content/en/sect/post.md", "/content/en/sect/post__de.md", "/content/en/sect/other.sv.md",
+		"/content/sv/sect/post__en.md", "/content/sv/sect/post__sv.md", "/content/sv/sect/post__fr.md",
+	} {
+		assert.NoError(afero.WriteFile(m, filepath.FromSlash(filename), []byte(filename), 0755))
+	}
+
+	newFs := func(lang string) *LanguageFs {
+		return NewLanguageFs(lang, languages, afero.NewBasePathFs(m, filepath.FromSlash("/content/"+lang)), WithTranslationInfo(translationInfo))
+	}
+
+	composite := NewLanguageCompositeFs(newFs("en"), newFs("sv"))
+
+	fis, err := afero.ReadDir(composite, "sect")
+	assert.NoError(err)
+
+	got := make(map[string]string)
+	for _, fi := range fis {
+		lfi := fi.(*LanguageFileInfo)
+		got[lfi.RealName()] = lfi.Lang() + " " + lfi.TranslationBaseName()
+	}
+
+	assert.Equal(map[string]string{
+		// post__en.md in the sv content dir is shadowed by post.md.
+		"post.md":     "en post",
+		"post__de.md": "de post",
+		"post__sv.md": "sv post",
+		// Not languages in this scheme.
+		"other.sv.md": "en other.sv",
+		"post__fr.md": "sv post__fr",
+	}, got)
+
+	paths, err := TranslationPaths(composite, "sect", "post")
+	assert.NoError(err)
+	assert.Equal(map[string]string{
+		"en": filepath.FromSlash("/content/en/sect/post.md"),
+		"de": filepath.FromSlash("/content/en/sect/post__de.md"),
+		"sv": filepath.FromSlash("/content/sv/sect/post__sv.md"),
+	}, paths)
+}
+
+func TestCompositeLanguageFsInfixDelimiter(t *testing.T) {
+	assert := require.New(t)
+
+	languages := map[string]bool{
+		"sv": true,
+		"en": true,
+		"de": true,
+	}
+
+	m := afero.NewMemMapFs()
+	for _, filename := range []string{
+		"/content/en/sect/post.md", "/content/en/sect/post@de.md", "/content/en/sect/other.sv.md",
+		"/content/sv/sect/post@en.md", "/content/sv/sect/post@sv.md", "/content/sv/sect/a@b@sv.md",
+	} {
+		assert.NoError(afero.WriteFile(m, filepath.FromSlash(filename), []byte(filename), 0755))
+	}
+
+	newFs := func(lang string) *LanguageFs {
+		return NewLanguageFs(lang, languages, afero.NewBasePathFs(m, filepath.FromSlash("/content/"+lang)), WithInfixDelimiter("@"))
+	}
+
+	composite := NewLanguageCompositeFs(newFs("en"), newFs("sv"))
+
+	fis, err := afero.ReadDir(composite, "sect")
+	assert.NoError(err)
+
+	got := make(map[string]string)
+	for _, fi := range fis {
+		lfi := fi.(*LanguageFileInfo)
+		got[lfi.RealName()] = lfi.Lang() + " " + lfi.TranslationBaseName()
+	}
+
+	assert.Equal(map[string]string{
+		// post@en.md in the sv content dir is shadowed by post.md.
+		"post.md":    "en post",
+		"post@de.md": "de post",
+		"post@sv.md": "sv post",
+		"a@b@sv.md":  "sv a@b",
+		// Not a language infix with this delimiter.
+		"other.sv.md": "en other.sv",
+	}, got)
+
+	b, err := afero.ReadFile(composite, filepath.FromSlash("sect/post@de.md"))
+	assert.NoError(err)
+	assert.Equal(filepath.FromSlash("/content/en/sect/post@de.md"), string(b))
+}
+
+func TestCompositeLanguageFsWeighter(t *testing.T) {
+	languages := map[string]bool{
+		"sv": true,
+		"en": true,
+	}
+
+	inverted := func(fileLang, fsLang string) int {
+		return 3 - defaultWeight(fileLang, fsLang)
+	}
+
+	for _, test := range []struct {
+		name     string
+		opts     []LanguageFsOption
+		expected string
+	}{
+		{"default", nil, "/content/en/sect/post.md"},
+		{"inverted", []LanguageFsOption{WithWeighter(inverted)}, "/content/sv/sect/post.en.md"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			assert := require.New(t)
+
+			m := afero.NewMemMapFs()
+			for _, filename := range []string{"/content/en/sect/post.md", "/content/sv/sect/post.en.md", "/content/sv/sect/post.md"} {
+				assert.NoError(afero.WriteFile(m, filepath.FromSlash(filename), []byte(filename), 0755))
+			}
+
+			newFs := func(lang string) *LanguageFs {
+				return NewLanguageFs(lang, languages, afero.NewBasePathFs(m, filepath.FromSlash("/content/"+lang)), test.opts...)
+			}
+
+			for _, composite := range []afero.Fs{
+				NewLanguageCompositeFs(newFs("en"), newFs("sv")),
+				NewLanguageCompositeFs(newFs("sv"), newFs("en")),
+			} {
+				fis, err := afero.ReadDir(composite, "sect")
+				assert.NoError(err)
+				assert.Len(fis, 2)
+
+				var en *LanguageFileInfo
+				for _, fi := range fis {
+					if lfi := fi.(*LanguageFileInfo); lfi.Lang() == "en" {
+						en = lfi
+					}
+				}
+				assert.NotNil(en)
+				assert.Equal(filepath.FromSlash(test.expected), en.Filename())
+
+				paths, err := TranslationPaths(composite, "sect", "post")
+				assert.NoError(err)
+				assert.Equal(filepath.FromSlash(test.expected), paths["en"])
+				assert.Equal(filepath.FromSlash("/content/sv/sect/post.md"), paths["sv"])
+			}
+		})
+	}
+}
@@ -17,7 +17,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/spf13/afero"
 )
@@ -53,26 +55,27 @@ type FilePather interface {
 
 // LanguageDirsMerger implements the afero.DirsMerger interface, which is used
 // to merge two directories.
+// An entry in bofi (the base layer) only replaces an entry from lofi (the
+// overlay) with the same virtual name if it has a higher weight, so for equal
+// weights the overlay, i.e. the filesystem added last in the composite, wins,
+// independent of the order the entries were read in.
+// Files with the same weight and virtual name in the same layer, e.g.
+// "post.md" and "post.en.md" in the en content dir, are resolved by the
+// InfixPolicy of their filesystem.
 var LanguageDirsMerger = func(lofi, bofi []os.FileInfo) ([]os.FileInfo, error) {
 	m := make(map[string]*LanguageFileInfo)
 
-	for _, fi := range lofi {
-		fil, ok := fi.(*LanguageFileInfo)
-		if !ok {
-			return nil, fmt.Errorf("received %T, expected *LanguageFileInfo", fi)
-		}
-		m[fil.virtualName] = fil
-	}
-
-	for _, fi := range bofi {
-		fil, ok := fi.(*LanguageFileInfo)
-		if !ok {
-			return nil, fmt.Errorf("received %T, expected *LanguageFileInfo", fi)
-		}
-		existing, found := m[fil.virtualName]
-
-		if !found || existing.weight < fil.weight {
-			m[fil.virtualName] = fil
+	for _, fis := range [][]os.FileInfo{lofi, bofi} {
+		for _, fi := range fis {
+			fil, ok := fi.(*LanguageFileInfo)
+			if !ok {
+				return nil, fmt.Errorf("received %T, expected *LanguageFileInfo", fi)
+			}
+			existing, found := m[fil.virtualName]
+
+			if !found || fil.outranks(existing) {
+				m[fil.virtualName] = fil
+			}
 		}
 	}
 
@@ -99,8 +102,20 @@ type LanguageFileInfo struct {
 	virtualName         string
 	translationBaseName string
 
-	// We add some weight to the files in their own language's content directory.
+	// By default we add some weight to the files in their own language's
+	// content directory, see WithWeighter.
 	weight int
+
+	// Set for the file preferred by the InfixPolicy.
+	preferred bool
+}
+
+// outranks reports whether fi should replace other with the same virtual name.
+func (fi *LanguageFileInfo) outranks(other *LanguageFileInfo) bool {
+	if fi.weight != other.weight {
+		return fi.weight > other.weight
+	}
+	return fi.preferred && !other.preferred
 }
 
 // Filename returns a file's real filename including the base (ie.
@@ -149,23 +164,51 @@ type languageFile struct {
 
 // Readdir creates FileInfo entries by calling Lstat if possible.
 func (l *languageFile) Readdir(c int) (ofi []os.FileInfo, err error) {
-	names, err := l.File.Readdirnames(c)
-	if err != nil {
-		return nil, err
-	}
+	for {
+		names, err := l.File.Readdirnames(c)
+		if err != nil {
+			return nil, err
+		}
 
-	fis := make([]os.FileInfo, len(names))
+		fis := make([]os.FileInfo, 0, len(names))
 
-	for i, name := range names {
-		fi, _, err := l.fs.LstatIfPossible(filepath.Join(l.Name(), name))
+		for _, name := range names {
+			fi, _, err := l.fs.lstatIfPossible(filepath.Join(l.Name(), name))
 
-		if err != nil {
-			return nil, err
+			if err != nil {
+				return nil, err
+			}
+			if l.fs.isHidden(fi) {
+				continue
+			}
+			fis = append(fis, fi)
+		}
+
+		// Make sure we don't return an empty slice without an error
+		// when reading in chunks.
+		if c <= 0 || len(fis) > 0 {
+			return fis, nil
 		}
-		fis[i] = fi
+	}
+}
+
+// Readdirnames returns the real names of the entries, without the files
+// hidden by WithContentExts.
+func (l *languageFile) Readdirnames(c int) ([]string, error) {
+	if l.fs.contentExts == nil {
+		return l.File.Readdirnames(c)
 	}
 
-	return fis, err
+	fis, err := l.Readdir(c)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(fis))
+	for i, fi := range fis {
+		names[i] = fi.(*LanguageFileInfo).RealName()
+	}
+	return names, nil
 }
 
 // LanguageFs represents a language filesystem.
@@ -175,11 +218,118 @@ type LanguageFs struct {
 	lang       string
 	nameMarker string
 	languages  map[string]bool
+
+	// If set, only regular files with these extensions are visible.
+	contentExts map[string]bool
+
+	// If set, file name infixes that are not a registered language but
+	// where this returns true are collected as warnings.
+	looksLikeLang func(infix string) bool
+
+	warningsMu sync.Mutex
+	warnings   map[string]bool
+
+	infixPolicy InfixPolicy
+
+	// Delimits the language infix in a file name, "." by default.
+	infixDelimiter string
+
+	// Weights a file in LanguageDirsMerger, see WithWeighter.
+	weighter func(fileLang, fsLang string) int
+
+	// If set, extracts the language and the translation base name from a
+	// file name instead of langInfoFrom.
+	translationInfo func(name string) (lang, translationBaseName string)
+
 	afero.Fs
 }
 
+// InfixPolicy decides which file wins when a file without a language infix,
+// e.g. "post.md" in the en content dir, and one with, e.g. "post.en.md",
+// resolve to the same language and name.
+type InfixPolicy int
+
+const (
+	// InfixFileWins prefers "post.en.md". This is the default.
+	InfixFileWins InfixPolicy = iota
+
+	// BareFileWins prefers "post.md".
+	BareFileWins
+)
+
+// LanguageFsOption configures a LanguageFs, see NewLanguageFs.
+type LanguageFsOption func(fs *LanguageFs)
+
+// WithContentExts hides the regular files that do not have one of the given
+// extensions, e.g. "md" or ".md" (case insensitive), from the language
+// filesystem, so they are never language parsed or merged.
+// Directories are always visible.
+func WithContentExts(exts ...string) LanguageFsOption {
+	return func(fs *LanguageFs) {
+		fs.contentExts = make(map[string]bool)
+		for _, ext := range exts {
+			fs.contentExts[strings.ToLower(strings.TrimPrefix(ext, "."))] = true
+		}
+	}
+}
+
+// WithInfixWarnings makes the language filesystem collect the files with a
+// language-like infix that is not a registered language, e.g. "post.enn.md",
+// available in Warnings. If looksLikeLang is nil, an infix of two or three
+// lowercase letters within one edit of a registered language is reported,
+// which catches typos but not secondary extensions such as "jquery.min.js".
+func WithInfixWarnings(looksLikeLang func(infix string) bool) LanguageFsOption {
+	return func(fs *LanguageFs) {
+		if looksLikeLang == nil {
+			looksLikeLang = fs.isLanguageTypo
+		}
+		fs.looksLikeLang = looksLikeLang
+		fs.warnings = make(map[string]bool)
+	}
+}
+
+// WithInfixPolicy sets the InfixPolicy of the language filesystem.
+func WithInfixPolicy(policy InfixPolicy) LanguageFsOption {
+	return func(fs *LanguageFs) {
+		fs.infixPolicy = policy
+	}
+}
+
+// WithTranslationInfo replaces how the language and the translation base name
+// are extracted from a file name, e.g. "post" and "de" for "post__de.md".
+// The default, langInfoFrom, treats the second-to-last extension as the language,
+// see WithInfixDelimiter.
+// If fn returns a language that is not registered, the file gets the language
+// of the filesystem and its translation base name is the name without the
+// extension.
+func WithTranslationInfo(fn func(name string) (lang, translationBaseName string)) LanguageFsOption {
+	return func(fs *LanguageFs) {
+		fs.translationInfo = fn
+	}
+}
+
+// WithInfixDelimiter sets the delimiter of the language infix, which is "."
+// by default, e.g. with "@" the file "post@de.md" gets the language "de" and
+// the translation base name "post".
+func WithInfixDelimiter(delim string) LanguageFsOption {
+	return func(fs *LanguageFs) {
+		fs.infixDelimiter = delim
+	}
+}
+
+// WithWeighter replaces how the weight of a file, used to pick one of the
+// files with the same language and name when merging language filesystems,
+// is calculated from the file's language and the filesystem's language.
+// The file with the higher weight wins, see LanguageDirsMerger. The default,
+// defaultWeight, prefers the files in their own language's content directory.
+func WithWeighter(weighter func(fileLang, fsLang string) int) LanguageFsOption {
+	return func(fs *LanguageFs) {
+		fs.weighter = weighter
+	}
+}
+
 // NewLanguageFs creates a new language filesystem.
-func NewLanguageFs(lang string, languages map[string]bool, fs afero.Fs) *LanguageFs {
+func NewLanguageFs(lang string, languages map[string]bool, fs afero.Fs, opts ...LanguageFsOption) *LanguageFs {
 	if lang == "" {
 		panic("no lang set for the language fs")
 	}
@@ -191,7 +341,74 @@ func NewLanguageFs(lang string, languages map[string]bool, fs afero.Fs) *Languag
 
 	marker := hugoFsMarker + "_" + lang + "_"
 
-	return &LanguageFs{lang: lang, languages: languages, basePath: basePath, Fs: fs, nameMarker: marker}
+	lfs := &LanguageFs{lang: lang, languages: languages, basePath: basePath, Fs: fs, nameMarker: marker, infixDelimiter: ".", weighter: defaultWeight}
+
+	for _, opt := range opts {
+		opt(lfs)
+	}
+
+	return lfs
+}
+
+// Warnings returns the sorted real filenames of the files seen with an
+// infix that looks like a language, but is not one, see WithInfixWarnings.
+func (fs *LanguageFs) Warnings() []string {
+	fs.warningsMu.Lock()
+	defer fs.warningsMu.Unlock()
+
+	warnings := make([]string, 0, len(fs.warnings))
+	for filename := range fs.warnings {
+		warnings = append(warnings, filename)
+	}
+	sort.Strings(warnings)
+
+	return warnings
+}
+
+func (fs *LanguageFs) addWarning(filename string) {
+	fs.warningsMu.Lock()
+	defer fs.warningsMu.Unlock()
+	fs.warnings[filename] = true
+}
+
+// isLanguageTypo reports whether infix is two or three lowercase letters and
+// one edit away from a registered language.
+func (fs *LanguageFs) isLanguageTypo(infix string) bool {
+	if len(infix) < 2 || len(infix) > 3 {
+		return false
+	}
+	for _, r := range infix {
+		if r < 'a' || r > 'z' {
+			return false
+		}
+	}
+	for lang := range fs.languages {
+		if oneEditApart(infix, lang) {
+			return true
+		}
+	}
+	return false
+}
+
+// oneEditApart reports whether a can be turned into b by inserting, removing
+// or replacing exactly one byte.
+func oneEditApart(a, b string) bool {
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+	if len(a)-len(b) > 1 || a == b {
+		return false
+	}
+
+	i := 0
+	for i < len(b) && a[i] == b[i] {
+		i++
+	}
+
+	if len(a) == len(b) {
+		return a[i+1:] == b[i+1:]
+	}
+	return a[i+1:] == b[i:]
 }
 
 // Lang returns a language filesystem's language (ie. "sv").
@@ -211,7 +428,16 @@ func (fs *LanguageFs) Stat(name string) (os.FileInfo, error) {
 		return nil, err
 	}
 
-	return fs.newLanguageFileInfo(name, fi)
+	lfi, err := fs.newLanguageFileInfo(name, fi)
+	if err != nil {
+		return nil, err
+	}
+
+	if fs.isHidden(lfi) {
+		return nil, &os.PathError{Op: "stat", Path: name, Err: os.ErrNotExist}
+	}
+
+	return lfi, nil
 }
 
 // Open opens the named file for reading.
@@ -225,6 +451,19 @@ func (fs *LanguageFs) Open(name string) (afero.File, error) {
 	if err != nil {
 		return nil, err
 	}
+
+	if fs.contentExts != nil && !fs.isContentExt(name) {
+		fi, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		if !fi.IsDir() {
+			f.Close()
+			return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+		}
+	}
+
 	return &languageFile{File: f, fs: fs}, nil
 }
 
@@ -232,6 +471,19 @@ func (fs *LanguageFs) Open(name string) (afero.File, error) {
 // It attempts to use Lstat if supported or defers to the os.  In addition to
 // the FileInfo, a boolean is returned telling whether Lstat was called.
 func (fs *LanguageFs) LstatIfPossible(name string) (os.FileInfo, bool, error) {
+	lfi, b, err := fs.lstatIfPossible(name)
+	if err != nil {
+		return nil, b, err
+	}
+
+	if fs.isHidden(lfi) {
+		return nil, b, &os.PathError{Op: "lstat", Path: name, Err: os.ErrNotExist}
+	}
+
+	return lfi, b, nil
+}
+
+func (fs *LanguageFs) lstatIfPossible(name string) (*LanguageFileInfo, bool, error) {
 	name, err := fs.realName(name)
 	if err != nil {
 		return nil, false, err
@@ -255,6 +507,16 @@ func (fs *LanguageFs) LstatIfPossible(name string) (os.FileInfo, bool, error) {
 	return lfi, b, err
 }
 
+// isHidden reports whether fi is a regular file hidden by WithContentExts.
+func (fs *LanguageFs) isHidden(fi *LanguageFileInfo) bool {
+	return fs.contentExts != nil && !fi.IsDir() && !fs.isContentExt(fi.RealName())
+}
+
+func (fs *LanguageFs) isContentExt(name string) bool {
+	ext := strings.TrimPrefix(filepath.Ext(name), ".")
+	return fs.contentExts[strings.ToLower(ext)]
+}
+
 func (fs *LanguageFs) realPath(name string) (string, error) {
 	if baseFs, ok := fs.Fs.(*afero.BasePathFs); ok {
 		return baseFs.RealPath(name)
@@ -277,6 +539,35 @@ func (fs *LanguageFs) realName(name string) (string, error) {
 	return strings.TrimPrefix(name, fs.basePath), nil
 }
 
+// defaultWeight adds some weight to a file if its language belongs in the
+// filesystem's directory, to make it more important.
+func defaultWeight(fileLang, fsLang string) int {
+	if fileLang == fsLang {
+		return 2
+	}
+	return 1
+}
+
+// langInfoFrom returns the part of name between the last delim and the
+// extension as the language and the part before it as the translation base
+// name, e.g. "en" and "post" for "post.en.md" and ".". The language is empty
+// if there is no delim before the extension.
+func langInfoFrom(name, delim string) (lang, translationBaseName string) {
+	baseNameNoExt := strings.TrimSuffix(name, filepath.Ext(name))
+	i := strings.LastIndex(baseNameNoExt, delim)
+	if i < 0 {
+		return "", baseNameNoExt
+	}
+	return baseNameNoExt[i+len(delim):], baseNameNoExt[:i]
+}
+
+func (fs *LanguageFs) langInfoFrom(name string) (lang, translationBaseName string) {
+	if fs.translationInfo != nil {
+		return fs.translationInfo(name)
+	}
+	return langInfoFrom(name, fs.infixDelimiter)
+}
+
 func (fs *LanguageFs) newLanguageFileInfo(filename string, fi os.FileInfo) (*LanguageFileInfo, error) {
 	filename = filepath.Clean(filename)
 	_, name := filepath.Split(filename)
@@ -292,26 +583,26 @@ func (fs *LanguageFs) newLanguageFileInfo(filename string, fi os.FileInfo) (*Lan
 	lang := fs.Lang()
 
 	baseNameNoExt := ""
+	preferred := false
 
 	if !fi.IsDir() {
 
 		// Try to extract the language from the file name.
 		// Any valid language identificator in the name will win over the
 		// language set on the file system, e.g. "mypost.en.md".
-		baseName := filepath.Base(name)
-		ext := filepath.Ext(baseName)
-		baseNameNoExt = baseName
+		ext := filepath.Ext(name)
+		baseNameNoExt = strings.TrimSuffix(name, ext)
 
-		if ext != "" {
-			baseNameNoExt = strings.TrimSuffix(baseNameNoExt, ext)
-		}
+		fileLang, translationBaseName := fs.langInfoFrom(name)
 
-		fileLangExt := filepath.Ext(baseNameNoExt)
-		fileLang := strings.TrimPrefix(fileLangExt, ".")
+		hasInfix := fs.languages[fileLang]
+		preferred = hasInfix == (fs.infixPolicy == InfixFileWins)
 
-		if fs.languages[fileLang] {
+		if hasInfix {
 			lang = fileLang
-			baseNameNoExt = strings.TrimSuffix(baseNameNoExt, fileLangExt)
+			baseNameNoExt = translationBaseName
+		} else if fileLang != "" && fs.looksLikeLang != nil && fs.looksLikeLang(fileLang) {
+			fs.addWarning(realPath)
 		}
 
 		// This connects the filename to the filesystem, not the language.
@@ -320,12 +611,7 @@ func (fs *LanguageFs) newLanguageFileInfo(filename string, fi os.FileInfo) (*Lan
 		name = fs.nameMarker + name
 	}
 
-	weight := 1
-	// If this file's language belongs in this directory, add some weight to it
-	// to make it more important.
-	if lang == fs.Lang() {
-		weight = 2
-	}
+	weight := fs.weighter(lang, fs.Lang())
 
 	if fi.IsDir() {
 		// For directories we always want to start from the union view.
@@ -335,6 +621,7 @@ func (fs *LanguageFs) newLanguageFileInfo(filename string, fi os.FileInfo) (*Lan
 	return &LanguageFileInfo{
 		lang:                lang,
 		weight:              weight,
+		preferred:           preferred,
 		realFilename:        realPath,
 		realName:            realName,
 		relFilename:         strings.TrimPrefix(strings.TrimPrefix(realPath, fs.basePath), string(os.PathSeparator)),
@@ -14,6 +14,7 @@
 package hugofs
 
 import (
+	"os"
 	"path/filepath"
 	"testing"
 
@@ -98,3 +99,203 @@ func TestFilenamesHandling(t *testing.T) {
 	}
 
 }
+
+func TestIndexFilesHandling(t *testing.T) {
+	languages := map[string]bool{
+		"sv": true,
+		"en": true,
+		"de": true,
+	}
+	assert := require.New(t)
+	m := afero.NewMemMapFs()
+	lfs := NewLanguageFs("sv", languages, afero.NewBasePathFs(m, filepath.FromSlash("/my/base")))
+
+	for _, test := range []struct {
+		filename            string
+		lang                string
+		translationBaseName string
+		virtualName         string
+	}{
+		{"sect/_index.md", "sv", "_index", "_index.sv.md"},
+		{"sect/_index.en.md", "en", "_index", "_index.en.md"},
+		{"sect/index.md", "sv", "index", "index.sv.md"},
+		{"sect/index.de.md", "de", "index", "index.de.md"},
+		// Not a language.
+		{"sect/_index.fr.md", "sv", "_index.fr", "_index.fr.sv.md"},
+	} {
+		filename := filepath.FromSlash(test.filename)
+		assert.NoError(afero.WriteFile(lfs, filename, []byte("abc"), 0777))
+		fi, err := lfs.Stat(filename)
+		assert.NoError(err)
+
+		lfi, ok := fi.(*LanguageFileInfo)
+		assert.True(ok)
+		assert.Equal(test.lang, lfi.Lang(), test.filename)
+		assert.Equal(test.translationBaseName, lfi.TranslationBaseName(), test.filename)
+		assert.Equal(test.virtualName, lfi.virtualName, test.filename)
+		assert.Equal(filepath.Base(filename), lfi.RealName(), test.filename)
+	}
+}
+
+func TestLanguageFsContentExts(t *testing.T) {
+	languages := map[string]bool{
+		"sv": true,
+		"en": true,
+	}
+	assert := require.New(t)
+	m := afero.NewMemMapFs()
+	base := filepath.FromSlash("/content/sv")
+	lfs := NewLanguageFs("sv", languages, afero.NewBasePathFs(m, base), WithContentExts(".md"))
+
+	for _, filename := range []string{"sect/page.md", "sect/page.en.MD", "sect/data.en.bin", "sect/blob.bin", "sect/dir.bin/p1.md"} {
+		assert.NoError(afero.WriteFile(m, filepath.Join(base, filepath.FromSlash(filename)), []byte(filename), 0755))
+	}
+
+	fis, err := afero.ReadDir(lfs, "sect")
+	assert.NoError(err)
+	var names []string
+	for _, fi := range fis {
+		names = append(names, fi.(*LanguageFileInfo).RealName())
+	}
+	assert.ElementsMatch([]string{"dir.bin", "page.en.MD", "page.md"}, names)
+
+	for _, filename := range []string{"sect/data.en.bin", "sect/blob.bin"} {
+		filename = filepath.FromSlash(filename)
+		_, err := lfs.Stat(filename)
+		assert.True(os.IsNotExist(err), filename)
+		_, _, err = lfs.LstatIfPossible(filename)
+		assert.True(os.IsNotExist(err), filename)
+		_, err = lfs.Open(filename)
+		assert.True(os.IsNotExist(err), filename)
+	}
+
+	fi, err := lfs.Stat(filepath.FromSlash("sect/page.md"))
+	assert.NoError(err)
+	lfi := fi.(*LanguageFileInfo)
+	assert.Equal(filepath.FromSlash("/content/sv/sect/page.md"), lfi.Filename())
+	assert.Equal(base, lfi.BaseDir())
+
+	b, err := afero.ReadFile(lfs, filepath.FromSlash("sect/dir.bin/p1.md"))
+	assert.NoError(err)
+	assert.Equal("sect/dir.bin/p1.md", string(b))
+
+	// Read in chunks.
+	f, err := lfs.Open("sect")
+	assert.NoError(err)
+	defer f.Close()
+	count := 0
+	for {
+		fis, err := f.Readdir(1)
+		if err != nil {
+			break
+		}
+		assert.Len(fis, 1)
+		count++
+	}
+	assert.Equal(3, count)
+
+	f2, err := lfs.Open("sect")
+	assert.NoError(err)
+	defer f2.Close()
+	names, err = f2.Readdirnames(-1)
+	assert.NoError(err)
+	assert.ElementsMatch([]string{"dir.bin", "page.en.MD", "page.md"}, names)
+
+	var walked []string
+	assert.NoError(afero.Walk(lfs, "sect", func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		walked = append(walked, filepath.ToSlash(path))
+		return nil
+	}))
+	assert.Equal([]string{"sect", "sect/dir.bin", "sect/dir.bin/p1.md", "sect/page.en.MD", "sect/page.md"}, walked)
+
+	langs, err := LanguagesInTree(lfs, "")
+	assert.NoError(err)
+	assert.Equal([]string{"en", "sv"}, langs)
+
+	// Merged with a filesystem without the option.
+	men := afero.NewMemMapFs()
+	baseEn := filepath.FromSlash("/content/en")
+	assert.NoError(afero.WriteFile(men, filepath.Join(baseEn, "sect", "blob.bin"), []byte("en blob"), 0755))
+	composite := NewLanguageCompositeFs(NewLanguageFs("en", languages, afero.NewBasePathFs(men, baseEn)), lfs)
+
+	fis, err = afero.ReadDir(composite, "sect")
+	assert.NoError(err)
+	assert.Len(fis, 4)
+	for _, fi := range fis {
+		lfi := fi.(*LanguageFileInfo)
+		if lfi.RealName() == "blob.bin" {
+			assert.Equal(filepath.Join(baseEn, "sect", "blob.bin"), lfi.Filename())
+		}
+	}
+}
+
+func TestLanguageFsInfixWarnings(t *testing.T) {
+	languages := map[string]bool{
+		"sv": true,
+		"en": true,
+	}
+	assert := require.New(t)
+	m := afero.NewMemMapFs()
+	base := filepath.FromSlash("/content/sv")
+
+	files := []string{"post.enn.md", "post.sve.md", "post.en.md", "post.de.md", "jquery.min.js", "archive.tar.gz", "class.Color.css"}
+	for _, filename := range files {
+		assert.NoError(afero.WriteFile(m, filepath.Join(base, "sect", filename), []byte(filename), 0755))
+	}
+
+	readdir := func(lfs *LanguageFs) {
+		// Read twice to make sure every file is reported once.
+		for i := 0; i < 2; i++ {
+			fis, err := afero.ReadDir(lfs, "sect")
+			assert.NoError(err)
+			assert.Len(fis, len(files))
+		}
+	}
+
+	lfs := NewLanguageFs("sv", languages, afero.NewBasePathFs(m, base), WithInfixWarnings(nil))
+	readdir(lfs)
+	assert.Equal([]string{
+		filepath.FromSlash("/content/sv/sect/post.enn.md"),
+		filepath.FromSlash("/content/sv/sect/post.sve.md"),
+	}, lfs.Warnings())
+
+	// The typo is not treated as a language.
+	fi, err := lfs.Stat(filepath.FromSlash("sect/post.enn.md"))
+	assert.NoError(err)
+	assert.Equal("sv", fi.(*LanguageFileInfo).Lang())
+	assert.Equal("post.enn", fi.(*LanguageFileInfo).TranslationBaseName())
+
+	lfs = NewLanguageFs("sv", languages, afero.NewBasePathFs(m, base), WithInfixWarnings(func(infix string) bool {
+		return infix == "min"
+	}))
+	readdir(lfs)
+	assert.Equal([]string{filepath.FromSlash("/content/sv/sect/jquery.min.js")}, lfs.Warnings())
+
+	lfs = NewLanguageFs("sv", languages, afero.NewBasePathFs(m, base))
+	readdir(lfs)
+	assert.Empty(lfs.Warnings())
+}
+
+func TestOneEditApart(t *testing.T) {
+	assert := require.New(t)
+
+	for _, test := range []struct {
+		a, b     string
+		expected bool
+	}{
+		{"enn", "en", true},
+		{"en", "enn", true},
+		{"nen", "en", true},
+		{"em", "en", true},
+		{"en", "en", false},
+		{"ne", "en", false},
+		{"min", "en", false},
+		{"e", "en", true},
+		{"", "en", false},
+	} {
+		assert.Equal(test.expected, oneEditApart(test.a, test.b), test.a+" "+test.b)
+	}
+}
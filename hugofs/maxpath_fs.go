@@ -0,0 +1,168 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+var (
+	_ afero.Fs      = (*maxPathFs)(nil)
+	_ afero.Lstater = (*maxPathFs)(nil)
+)
+
+// defaultMaxPathLength returns the maximum path length for the current
+// platform: MAX_PATH on Windows and PATH_MAX on others, which is 1024 on
+// macOS and the BSDs and 4096 on Linux.
+func defaultMaxPathLength() int {
+	switch runtime.GOOS {
+	case "windows":
+		return 260
+	case "darwin", "ios", "freebsd", "openbsd", "netbsd", "dragonfly":
+		return 1024
+	}
+	return 4096
+}
+
+// NewMaxPathFs creates a new filesystem that rejects paths longer than max
+// bytes with an *os.PathError naming the path, before passing the call to
+// fs. This replaces the obscure errors some platforms give for long paths.
+// If max is <= 0, a platform-appropriate default is used.
+// If fs is a *afero.BasePathFs, the length of the real path is checked.
+func NewMaxPathFs(fs afero.Fs, max int) afero.Fs {
+	if max <= 0 {
+		max = defaultMaxPathLength()
+	}
+	return &maxPathFs{Fs: fs, max: max}
+}
+
+type maxPathFs struct {
+	afero.Fs
+	max int
+}
+
+func (fs *maxPathFs) check(op, name string) error {
+	length := len(name)
+	if bfs, ok := fs.Fs.(*afero.BasePathFs); ok {
+		if realPath, err := bfs.RealPath(name); err == nil {
+			length = len(realPath)
+		}
+	}
+
+	if length > fs.max {
+		return &os.PathError{Op: op, Path: name, Err: fmt.Errorf("path length %d exceeds the maximum of %d", length, fs.max)}
+	}
+
+	return nil
+}
+
+func (fs *maxPathFs) Create(name string) (afero.File, error) {
+	if err := fs.check("create", name); err != nil {
+		return nil, err
+	}
+	return fs.Fs.Create(name)
+}
+
+func (fs *maxPathFs) Mkdir(name string, perm os.FileMode) error {
+	if err := fs.check("mkdir", name); err != nil {
+		return err
+	}
+	return fs.Fs.Mkdir(name, perm)
+}
+
+func (fs *maxPathFs) MkdirAll(path string, perm os.FileMode) error {
+	if err := fs.check("mkdir", path); err != nil {
+		return err
+	}
+	return fs.Fs.MkdirAll(path, perm)
+}
+
+func (fs *maxPathFs) Open(name string) (afero.File, error) {
+	if err := fs.check("open", name); err != nil {
+		return nil, err
+	}
+	return fs.Fs.Open(name)
+}
+
+func (fs *maxPathFs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	if err := fs.check("open", name); err != nil {
+		return nil, err
+	}
+	return fs.Fs.OpenFile(name, flag, perm)
+}
+
+func (fs *maxPathFs) Remove(name string) error {
+	if err := fs.check("remove", name); err != nil {
+		return err
+	}
+	return fs.Fs.Remove(name)
+}
+
+func (fs *maxPathFs) RemoveAll(path string) error {
+	if err := fs.check("removeall", path); err != nil {
+		return err
+	}
+	return fs.Fs.RemoveAll(path)
+}
+
+func (fs *maxPathFs) Rename(oldname, newname string) error {
+	if err := fs.check("rename", oldname); err != nil {
+		return err
+	}
+	if err := fs.check("rename", newname); err != nil {
+		return err
+	}
+	return fs.Fs.Rename(oldname, newname)
+}
+
+func (fs *maxPathFs) Stat(name string) (os.FileInfo, error) {
+	if err := fs.check("stat", name); err != nil {
+		return nil, err
+	}
+	return fs.Fs.Stat(name)
+}
+
+func (fs *maxPathFs) LstatIfPossible(name string) (os.FileInfo, bool, error) {
+	if err := fs.check("lstat", name); err != nil {
+		return nil, false, err
+	}
+	if lfs, ok := fs.Fs.(afero.Lstater); ok {
+		return lfs.LstatIfPossible(name)
+	}
+	fi, err := fs.Fs.Stat(name)
+	return fi, false, err
+}
+
+func (fs *maxPathFs) Chmod(name string, mode os.FileMode) error {
+	if err := fs.check("chmod", name); err != nil {
+		return err
+	}
+	return fs.Fs.Chmod(name, mode)
+}
+
+func (fs *maxPathFs) Chtimes(name string, atime time.Time, mtime time.Time) error {
+	if err := fs.check("chtimes", name); err != nil {
+		return err
+	}
+	return fs.Fs.Chtimes(name, atime, mtime)
+}
+
+func (fs *maxPathFs) Name() string {
+	return "maxPathFs"
+}
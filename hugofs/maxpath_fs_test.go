@@ -0,0 +1,70 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxPathFs(t *testing.T) {
+	assert := require.New(t)
+
+	m := afero.NewMemMapFs()
+	fs := NewMaxPathFs(m, 20)
+
+	assert.NoError(afero.WriteFile(fs, filepath.FromSlash("content/p1.md"), []byte("p1"), 0755))
+	b, err := afero.ReadFile(fs, filepath.FromSlash("content/p1.md"))
+	assert.NoError(err)
+	assert.Equal("p1", string(b))
+
+	long := filepath.FromSlash("content/" + strings.Repeat("a", 20) + ".md")
+
+	_, err = fs.Open(long)
+	assert.Error(err)
+	perr, ok := err.(*os.PathError)
+	assert.True(ok)
+	assert.Equal(long, perr.Path)
+	assert.Contains(err.Error(), "path length 31 exceeds the maximum of 20")
+
+	_, err = fs.Stat(long)
+	assert.Error(err)
+	assert.False(os.IsNotExist(err))
+	_, err = fs.Create(long)
+	assert.Error(err)
+	assert.Error(fs.MkdirAll(long, 0755))
+	assert.Error(fs.Rename(filepath.FromSlash("content/p1.md"), long))
+
+	_, err = m.Stat(long)
+	assert.True(os.IsNotExist(err))
+
+	// The real path is checked for BasePathFs.
+	bfs := NewMaxPathFs(afero.NewBasePathFs(m, filepath.FromSlash("/long/base/path")), 20)
+	_, err = bfs.Stat(filepath.FromSlash("content/p1.md"))
+	assert.Error(err)
+	assert.Contains(err.Error(), "exceeds the maximum of 20")
+
+	// Default limit.
+	fs = NewMaxPathFs(m, 0)
+	_, err = fs.Stat(filepath.FromSlash("content/p1.md"))
+	assert.NoError(err)
+	_, err = fs.Stat(strings.Repeat("a", defaultMaxPathLength()+1))
+	assert.Error(err)
+	assert.False(os.IsNotExist(err))
+}
@@ -26,7 +26,9 @@ var (
 	_       afero.Fs = (*noOpFs)(nil)
 
 	// NoOpFs provides a no-op filesystem that implements the afero.Fs
-	// interface.
+	// interface. It behaves like an empty, read-only filesystem: Open, OpenFile
+	// and Stat return os.ErrNotExist for every name (including the root, so
+	// there is nothing to Readdir), and all write operations fail.
 	NoOpFs = &noOpFs{}
 )
 
@@ -0,0 +1,44 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"os"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNoOpFs(t *testing.T) {
+	assert := require.New(t)
+
+	for _, name := range []string{"", "/", "a.txt", "/a/b.txt"} {
+		_, err := NoOpFs.Open(name)
+		assert.True(os.IsNotExist(err), name)
+		_, err = NoOpFs.OpenFile(name, os.O_RDONLY, 0)
+		assert.True(os.IsNotExist(err), name)
+		_, err = NoOpFs.Stat(name)
+		assert.True(os.IsNotExist(err), name)
+	}
+
+	fis, err := afero.ReadDir(NoOpFs, "/")
+	assert.True(os.IsNotExist(err))
+	assert.Empty(fis)
+
+	_, err = NoOpFs.Create("a.txt")
+	assert.Equal(errNoOp, err)
+	assert.Equal(errNoOp, NoOpFs.MkdirAll("a", 0755))
+	assert.Equal(errNoOp, NoOpFs.Remove("a.txt"))
+}
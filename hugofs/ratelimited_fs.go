@@ -0,0 +1,107 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+var (
+	_ afero.Fs      = (*rateLimitedFs)(nil)
+	_ afero.Lstater = (*rateLimitedFs)(nil)
+)
+
+// NewRateLimitedFs creates a new filesystem that limits the rate of Open,
+// OpenFile, Stat and LstatIfPossible calls to opsPerSecond, allowing bursts
+// of up to burst operations. Callers above the rate will block.
+// This is useful for filesystems backed by remote storage with rate limits.
+func NewRateLimitedFs(fs afero.Fs, opsPerSecond float64, burst int) afero.Fs {
+	return &rateLimitedFs{Fs: fs, limiter: newTokenBucket(opsPerSecond, burst)}
+}
+
+type rateLimitedFs struct {
+	afero.Fs
+	limiter *tokenBucket
+}
+
+func (fs *rateLimitedFs) Open(name string) (afero.File, error) {
+	fs.limiter.wait()
+	return fs.Fs.Open(name)
+}
+
+func (fs *rateLimitedFs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	fs.limiter.wait()
+	return fs.Fs.OpenFile(name, flag, perm)
+}
+
+func (fs *rateLimitedFs) Stat(name string) (os.FileInfo, error) {
+	fs.limiter.wait()
+	return fs.Fs.Stat(name)
+}
+
+func (fs *rateLimitedFs) LstatIfPossible(name string) (os.FileInfo, bool, error) {
+	fs.limiter.wait()
+	if lfs, ok := fs.Fs.(afero.Lstater); ok {
+		return lfs.LstatIfPossible(name)
+	}
+	fi, err := fs.Fs.Stat(name)
+	return fi, false, err
+}
+
+func (fs *rateLimitedFs) Name() string {
+	return "rateLimitedFs"
+}
+
+// tokenBucket is a simple token bucket rate limiter. The token count may
+// go negative; callers then sleep until their token is due, which
+// keeps concurrent callers in order without a background goroutine.
+type tokenBucket struct {
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	b := math.Max(1, float64(burst))
+	return &tokenBucket{rate: rate, burst: b, tokens: b, last: time.Now()}
+}
+
+func (b *tokenBucket) wait() {
+	if b.rate <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	b.tokens--
+	var d time.Duration
+	if b.tokens < 0 {
+		d = time.Duration(-b.tokens / b.rate * float64(time.Second))
+	}
+	b.mu.Unlock()
+
+	if d > 0 {
+		time.Sleep(d)
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitedFs(t *testing.T) {
+	assert := require.New(t)
+
+	m := afero.NewMemMapFs()
+	assert.NoError(afero.WriteFile(m, "f.txt", []byte("content"), 0755))
+
+	fs := NewRateLimitedFs(m, 100, 2)
+	_, ok := fs.(afero.Lstater)
+	assert.True(ok)
+
+	start := time.Now()
+
+	// 2 operations are covered by the burst, the next 10 should take
+	// about 100ms at 100 operations per second.
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 3; j++ {
+				if _, err := fs.Stat("f.txt"); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	elapsed := time.Since(start)
+	assert.True(elapsed >= 90*time.Millisecond, elapsed)
+	assert.True(elapsed < 2*time.Second, elapsed)
+
+	b, err := afero.ReadFile(fs, "f.txt")
+	assert.NoError(err)
+	assert.Equal("content", string(b))
+}
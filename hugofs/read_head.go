@@ -0,0 +1,50 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/afero"
+)
+
+// ReadHead reads up to n bytes from the start of the named file. Reading
+// a file shorter than n is not an error; the full content is returned.
+// This is useful for content sniffing without reading large files.
+// For n == 0 an empty slice is returned if the file can be opened, a negative
+// n is an error.
+func ReadHead(fs afero.Fs, filename string, n int) ([]byte, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("ReadHead: negative byte count %d", n)
+	}
+
+	f, err := fs.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if n == 0 {
+		return []byte{}, nil
+	}
+
+	b := make([]byte, n)
+	m, err := io.ReadFull(f, b)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = nil
+	}
+
+	return b[:m], err
+}
@@ -0,0 +1,58 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadHead(t *testing.T) {
+	assert := require.New(t)
+
+	base := afero.NewMemMapFs()
+	overlay := afero.NewMemMapFs()
+	fs := afero.NewReadOnlyFs(afero.NewCopyOnWriteFs(base, overlay))
+
+	large := "---\ntitle: large\n---\n" + strings.Repeat("a", 1<<20)
+	afero.WriteFile(base, filepath.FromSlash("/content/large.md"), []byte(large), 0755)
+	afero.WriteFile(overlay, filepath.FromSlash("/content/short.md"), []byte("short"), 0755)
+
+	b, err := ReadHead(fs, filepath.FromSlash("/content/large.md"), 3)
+	assert.NoError(err)
+	assert.Equal("---", string(b))
+
+	b, err = ReadHead(fs, filepath.FromSlash("/content/short.md"), 512)
+	assert.NoError(err)
+	assert.Equal("short", string(b))
+
+	_, err = ReadHead(fs, filepath.FromSlash("/content/missing.md"), 512)
+	assert.True(os.IsNotExist(err))
+
+	b, err = ReadHead(fs, filepath.FromSlash("/content/short.md"), 0)
+	assert.NoError(err)
+	assert.Empty(b)
+	assert.NotNil(b)
+
+	_, err = ReadHead(fs, filepath.FromSlash("/content/missing.md"), 0)
+	assert.True(os.IsNotExist(err))
+
+	_, err = ReadHead(fs, filepath.FromSlash("/content/short.md"), -1)
+	assert.Error(err)
+}
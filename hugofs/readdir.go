@@ -0,0 +1,49 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"os"
+
+	"github.com/spf13/afero"
+)
+
+// ReadDirsOnly reads the directory named by name and returns its
+// subdirectories sorted by filename.
+func ReadDirsOnly(fs afero.Fs, name string) ([]os.FileInfo, error) {
+	return readDirFiltered(fs, name, true)
+}
+
+// ReadFilesOnly reads the directory named by name and returns the files in it,
+// i.e. everything but directories, sorted by filename.
+func ReadFilesOnly(fs afero.Fs, name string) ([]os.FileInfo, error) {
+	return readDirFiltered(fs, name, false)
+}
+
+func readDirFiltered(fs afero.Fs, name string, dirs bool) ([]os.FileInfo, error) {
+	fis, err := afero.ReadDir(fs, name)
+	if err != nil {
+		return nil, err
+	}
+
+	n := 0
+	for _, fi := range fis {
+		if fi.IsDir() == dirs {
+			fis[n] = fi
+			n++
+		}
+	}
+
+	return fis[:n], nil
+}
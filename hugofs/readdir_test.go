@@ -0,0 +1,52 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadDirsAndFilesOnly(t *testing.T) {
+	assert := require.New(t)
+
+	fs := newTestUnionFs(t)
+	root := filepath.FromSlash("/content")
+
+	names := func(fis []os.FileInfo) []string {
+		var names []string
+		for _, fi := range fis {
+			names = append(names, fi.Name())
+		}
+		return names
+	}
+
+	dirs, err := ReadDirsOnly(fs, root)
+	assert.NoError(err)
+	assert.Equal([]string{"sect"}, names(dirs))
+
+	files, err := ReadFilesOnly(fs, root)
+	assert.NoError(err)
+	assert.Equal([]string{"about.md"}, names(files))
+
+	files, err = ReadFilesOnly(fs, filepath.Join(root, "sect"))
+	assert.NoError(err)
+	assert.Equal([]string{"p1.md", "p2.md"}, names(files))
+
+	_, err = ReadFilesOnly(fs, filepath.Join(root, "nope"))
+	assert.True(os.IsNotExist(err))
+}
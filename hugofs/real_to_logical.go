@@ -0,0 +1,49 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"os"
+	"sort"
+
+	"github.com/spf13/afero"
+)
+
+// RealToLogical walks the files below root and returns a map from the real
+// filename of every file to the sorted, slash separated paths relative to
+// root it is available as. A file mounted into several roots (e.g. via
+// RootMappingFs) will have more than one path.
+// This is useful to map a changed file on disk back to the paths affected.
+func RealToLogical(fs afero.Fs, root string) (map[string][]string, error) {
+	m := make(map[string][]string)
+
+	err := walkLogical(fs, root, func(filename, logical string, fi os.FileInfo) error {
+		if fi.IsDir() {
+			return nil
+		}
+		real := realFilename(fi, filename)
+		m[real] = append(m[real], logical)
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, paths := range m {
+		sort.Strings(paths)
+	}
+
+	return m, nil
+}
@@ -0,0 +1,56 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRealToLogical(t *testing.T) {
+	assert := require.New(t)
+
+	m := afero.NewMemMapFs()
+	afero.WriteFile(m, filepath.FromSlash("/shared/logo.png"), []byte("logo"), 0755)
+	afero.WriteFile(m, filepath.FromSlash("/shared/css/main.css"), []byte("css"), 0755)
+	afero.WriteFile(m, filepath.FromSlash("/site/static/robots.txt"), []byte("robots"), 0755)
+
+	// /shared is mounted twice.
+	rfs, err := NewRootMappingFs(m,
+		"assets", filepath.FromSlash("/shared"),
+		"static", filepath.FromSlash("/site/static"),
+		"media", filepath.FromSlash("/shared"),
+	)
+	assert.NoError(err)
+
+	got, err := RealToLogical(rfs, "")
+	assert.NoError(err)
+
+	assert.Equal(map[string][]string{
+		filepath.FromSlash("/shared/logo.png"):        {"assets/logo.png", "media/logo.png"},
+		filepath.FromSlash("/shared/css/main.css"):    {"assets/css/main.css", "media/css/main.css"},
+		filepath.FromSlash("/site/static/robots.txt"): {"static/robots.txt"},
+	}, got)
+
+	got, err = RealToLogical(rfs, "media")
+	assert.NoError(err)
+
+	assert.Equal(map[string][]string{
+		filepath.FromSlash("/shared/logo.png"):     {"logo.png"},
+		filepath.FromSlash("/shared/css/main.css"): {"css/main.css"},
+	}, got)
+}
@@ -0,0 +1,42 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"os"
+)
+
+// SameBacking reports whether a and b describe the same underlying file,
+// e.g. two hard links to the same bytes. Any wrapping done by this package,
+// e.g. LanguageFileInfo, is removed before the check.
+// This depends on os.SameFile, so it only returns true for os.FileInfo values
+// that come from the OS filesystem on platforms that support it.
+func SameBacking(a, b os.FileInfo) bool {
+	return os.SameFile(unwrapFileInfo(a), unwrapFileInfo(b))
+}
+
+func unwrapFileInfo(fi os.FileInfo) os.FileInfo {
+	for {
+		switch v := fi.(type) {
+		case *LanguageFileInfo:
+			fi = v.FileInfo
+		case *realFilenameInfo:
+			fi = v.FileInfo
+		case *fixedModTimeFileInfo:
+			fi = v.FileInfo
+		default:
+			return fi
+		}
+	}
+}
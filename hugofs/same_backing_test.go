@@ -0,0 +1,66 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSameBacking(t *testing.T) {
+	assert := require.New(t)
+
+	dir, err := ioutil.TempDir("", "hugofs")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	p1 := filepath.Join(dir, "p1.md")
+	p2 := filepath.Join(dir, "p2.md")
+	p3 := filepath.Join(dir, "p3.md")
+
+	assert.NoError(ioutil.WriteFile(p1, []byte("content"), 0755))
+	assert.NoError(ioutil.WriteFile(p3, []byte("content"), 0755))
+
+	if err := os.Link(p1, p2); err != nil {
+		t.Skipf("hard links not supported: %s", err)
+	}
+
+	fs := NewFixedModTimeFs(NewLanguageFs("en", map[string]bool{"en": true}, afero.NewBasePathFs(afero.NewOsFs(), dir)), time.Now())
+
+	stat := func(name string) os.FileInfo {
+		fi, err := fs.Stat(name)
+		assert.NoError(err)
+		return fi
+	}
+
+	fi1, fi2, fi3 := stat("p1.md"), stat("p2.md"), stat("p3.md")
+
+	assert.IsType(&LanguageFileInfo{}, fi1)
+	assert.True(SameBacking(fi1, fi2))
+	assert.True(SameBacking(fi1, fi1))
+	assert.False(SameBacking(fi1, fi3))
+
+	// Not supported for in-memory files.
+	m := afero.NewMemMapFs()
+	afero.WriteFile(m, "p1.md", []byte("content"), 0755)
+	mfi, err := m.Stat("p1.md")
+	assert.NoError(err)
+	assert.False(SameBacking(mfi, mfi))
+}
@@ -0,0 +1,102 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// Snapshot is a frozen, serializable view of a filesystem tree.
+type Snapshot struct {
+	// Entries are sorted by Path.
+	Entries []SnapshotEntry
+
+	// Whether the file content is embedded in the snapshot.
+	WithContent bool
+}
+
+// SnapshotEntry represents a file or directory in a Snapshot.
+type SnapshotEntry struct {
+	// Path is the slash separated path relative to the snapshot root.
+	// Files from a language filesystem have the language in the filename,
+	// e.g. "page.sv.md", so translations with the same name in different
+	// content dirs are all kept.
+	Path string
+
+	IsDir   bool
+	Mode    os.FileMode
+	Size    int64
+	ModTime time.Time
+
+	Content []byte `json:",omitempty"`
+}
+
+// SnapshotFs walks fs from root and captures the merged tree as seen by
+// Readdir into a Snapshot. If withContent is set, the file content is
+// stored in the snapshot, too.
+func SnapshotFs(fs afero.Fs, root string, withContent bool) (*Snapshot, error) {
+	snap := &Snapshot{WithContent: withContent}
+
+	err := walkLogical(fs, root, func(filename, logical string, fi os.FileInfo) error {
+		entry := SnapshotEntry{Path: uniqueLogicalPath(logical, fi), IsDir: fi.IsDir(), Mode: fi.Mode()}
+		if !fi.IsDir() {
+			entry.Size = fi.Size()
+			entry.ModTime = fi.ModTime()
+			if withContent {
+				b, err := afero.ReadFile(fs, filename)
+				if err != nil {
+					return err
+				}
+				entry.Content = b
+			}
+		}
+		snap.Entries = append(snap.Entries, entry)
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	// The walk is in the order of the names in fs, which for a language
+	// filesystem are the marked names, e.g. "__hugofs_sv_page.md".
+	sort.Slice(snap.Entries, func(i, j int) bool {
+		return snap.Entries[i].Path < snap.Entries[j].Path
+	})
+
+	return snap, nil
+}
+
+// NewSnapshotFs creates a read-only filesystem replaying the given snapshot.
+// Files in a snapshot without content will be empty.
+func NewSnapshotFs(snap *Snapshot) afero.Fs {
+	fs := afero.NewMemMapFs()
+
+	for _, e := range snap.Entries {
+		filename := filepath.FromSlash(e.Path)
+		if e.IsDir {
+			fs.MkdirAll(filename, e.Mode.Perm())
+			continue
+		}
+		afero.WriteFile(fs, filename, e.Content, e.Mode.Perm())
+		fs.Chtimes(filename, e.ModTime, e.ModTime)
+	}
+
+	return afero.NewReadOnlyFs(fs)
+}
@@ -0,0 +1,134 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestUnionFs(t testing.TB) afero.Fs {
+	base := afero.NewMemMapFs()
+	overlay := afero.NewMemMapFs()
+
+	for _, fs := range []afero.Fs{base, overlay} {
+		if err := fs.MkdirAll(filepath.FromSlash("/content/sect"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	afero.WriteFile(base, filepath.FromSlash("/content/sect/p1.md"), []byte("base p1"), 0755)
+	afero.WriteFile(base, filepath.FromSlash("/content/sect/p2.md"), []byte("base p2"), 0755)
+	afero.WriteFile(overlay, filepath.FromSlash("/content/sect/p1.md"), []byte("overlay p1"), 0755)
+	afero.WriteFile(overlay, filepath.FromSlash("/content/about.md"), []byte("overlay about"), 0755)
+
+	return afero.NewReadOnlyFs(afero.NewCopyOnWriteFs(base, overlay))
+}
+
+func TestSnapshotFs(t *testing.T) {
+	assert := require.New(t)
+
+	fs := newTestUnionFs(t)
+	root := filepath.FromSlash("/content")
+
+	snap, err := SnapshotFs(fs, root, true)
+	assert.NoError(err)
+	assert.Len(snap.Entries, 4)
+
+	// Round trip through JSON to make sure it's serializable.
+	b, err := json.Marshal(snap)
+	assert.NoError(err)
+	var decoded Snapshot
+	assert.NoError(json.Unmarshal(b, &decoded))
+
+	replay := NewSnapshotFs(&decoded)
+
+	readdir := func(fs afero.Fs, dir string) []os.FileInfo {
+		fis, err := afero.ReadDir(fs, dir)
+		assert.NoError(err)
+		return fis
+	}
+
+	for _, dir := range []string{"", "sect"} {
+		expected := readdir(fs, filepath.Join(root, dir))
+		got := readdir(replay, dir)
+		assert.Len(got, len(expected))
+		for i, fi := range expected {
+			assert.Equal(fi.Name(), got[i].Name())
+			assert.Equal(fi.IsDir(), got[i].IsDir())
+			if !fi.IsDir() {
+				assert.Equal(fi.Size(), got[i].Size())
+				assert.True(fi.ModTime().Equal(got[i].ModTime()))
+			}
+		}
+	}
+
+	for _, filename := range []string{"about.md", "sect/p1.md", "sect/p2.md"} {
+		expected, err := afero.ReadFile(fs, filepath.Join(root, filepath.FromSlash(filename)))
+		assert.NoError(err)
+		got, err := afero.ReadFile(replay, filepath.FromSlash(filename))
+		assert.NoError(err)
+		assert.Equal(string(expected), string(got))
+	}
+
+	_, err = replay.Create("new.md")
+	assert.Error(err)
+
+	snap, err = SnapshotFs(fs, root, false)
+	assert.NoError(err)
+	b, err = afero.ReadFile(NewSnapshotFs(snap), filepath.FromSlash("sect/p1.md"))
+	assert.NoError(err)
+	assert.Empty(b)
+}
+
+func TestSnapshotFsLanguageComposite(t *testing.T) {
+	assert := require.New(t)
+
+	languages := map[string]bool{
+		"sv": true,
+		"en": true,
+		"nn": true,
+	}
+
+	fs := newTestTranslationsFs(t, languages, map[string][]string{
+		"sv": {"sect/page.md", "sect/a.md"},
+		"en": {"sect/page.md", "sect/f.nn.md", "sect/b.md"},
+	})
+
+	snap, err := SnapshotFs(fs, "sect", true)
+	assert.NoError(err)
+
+	var paths []string
+	for _, e := range snap.Entries {
+		paths = append(paths, e.Path)
+	}
+	assert.Equal([]string{"a.sv.md", "b.en.md", "f.nn.md", "page.en.md", "page.sv.md"}, paths)
+
+	replay := NewSnapshotFs(snap)
+
+	for filename, expected := range map[string]string{
+		"f.nn.md":    "en sect/f.nn.md",
+		"page.en.md": "en sect/page.md",
+		"page.sv.md": "sv sect/page.md",
+	} {
+		b, err := afero.ReadFile(replay, filename)
+		assert.NoError(err)
+		assert.Equal(expected, string(b))
+	}
+}
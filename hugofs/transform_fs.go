@@ -0,0 +1,88 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+var _ afero.Fs = (*transformFs)(nil)
+
+// NewTransformFs creates a new filesystem where files opened for reading are
+// passed through the transform registered for their extension, e.g. "md" or
+// ".md" (case insensitive). Files with no registered transform and directories
+// are passed through untouched.
+//
+// Note that Size in the os.FileInfo values returned from Stat, Readdir and
+// the opened file's Stat is always the size of the original file, as the
+// transformed size is not known without reading the file.
+func NewTransformFs(fs afero.Fs, transforms map[string]func(io.Reader) io.Reader) afero.Fs {
+	m := make(map[string]func(io.Reader) io.Reader)
+	for ext, transform := range transforms {
+		m[strings.ToLower(strings.TrimPrefix(ext, "."))] = transform
+	}
+	return &transformFs{Fs: fs, transforms: m}
+}
+
+type transformFs struct {
+	afero.Fs
+	transforms map[string]func(io.Reader) io.Reader
+}
+
+func (fs *transformFs) Open(name string) (afero.File, error) {
+	f, err := fs.Fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	if fi.IsDir() {
+		return f, nil
+	}
+
+	ext := strings.TrimPrefix(filepath.Ext(realName(fi)), ".")
+	transform, found := fs.transforms[strings.ToLower(ext)]
+	if !found {
+		return f, nil
+	}
+
+	content, err := afero.ReadAll(transform(f))
+	f.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return newInMemoryFileWithInfo(name, content, fi), nil
+}
+
+func (fs *transformFs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	if !isWrite(flag) && flag&os.O_CREATE == 0 {
+		return fs.Open(name)
+	}
+	return fs.Fs.OpenFile(name, flag, perm)
+}
+
+func (fs *transformFs) Name() string {
+	return "transformFs"
+}
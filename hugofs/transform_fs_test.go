@@ -0,0 +1,82 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransformFs(t *testing.T) {
+	assert := require.New(t)
+
+	m := afero.NewMemMapFs()
+	for _, filename := range []string{"a.txt", "b.TXT", "c.md", "sect/d.txt"} {
+		assert.NoError(afero.WriteFile(m, filepath.Join("content", filepath.FromSlash(filename)), []byte("content "+filename), 0755))
+	}
+
+	upper := func(r io.Reader) io.Reader {
+		b, err := afero.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return bytes.NewReader(bytes.ToUpper(b))
+	}
+
+	fs := NewTransformFs(m, map[string]func(io.Reader) io.Reader{".txt": upper})
+
+	fis, err := afero.ReadDir(fs, "content")
+	assert.NoError(err)
+	assert.Len(fis, 4)
+
+	expected := map[string]string{
+		"a.txt": "CONTENT A.TXT",
+		"b.TXT": "CONTENT B.TXT",
+		"c.md":  "content c.md",
+	}
+
+	for _, fi := range fis {
+		if fi.IsDir() {
+			continue
+		}
+		filename := filepath.Join("content", fi.Name())
+		b, err := afero.ReadFile(fs, filename)
+		assert.NoError(err)
+		assert.Equal(expected[fi.Name()], string(b))
+
+		// Size is the original size.
+		assert.Equal(int64(len("content "+fi.Name())), fi.Size())
+	}
+
+	b, err := afero.ReadFile(fs, filepath.FromSlash("content/sect/d.txt"))
+	assert.NoError(err)
+	assert.Equal("CONTENT SECT/D.TXT", string(b))
+
+	// Writes are passed through.
+	f, err := fs.OpenFile(filepath.FromSlash("content/a.txt"), os.O_WRONLY|os.O_TRUNC, 0755)
+	assert.NoError(err)
+	_, err = f.Write([]byte("new"))
+	assert.NoError(err)
+	f.Close()
+
+	b, err = afero.ReadFile(fs, filepath.FromSlash("content/a.txt"))
+	assert.NoError(err)
+	assert.Equal("NEW", string(b))
+}
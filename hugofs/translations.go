@@ -0,0 +1,263 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// LanguagesInTree walks the files below root and returns the sorted set of
+// languages of the files found, as reported by LanguageAnnouncer.
+// This may differ from the configured languages, e.g. if a language
+// has no content.
+func LanguagesInTree(fs afero.Fs, root string) ([]string, error) {
+	seen := make(map[string]bool)
+
+	err := walkLogical(fs, root, func(filename, logical string, fi os.FileInfo) error {
+		if fi.IsDir() {
+			return nil
+		}
+		if la, ok := fi.(LanguageAnnouncer); ok && la.Lang() != "" {
+			seen[la.Lang()] = true
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	langs := make([]string, 0, len(seen))
+	for lang := range seen {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+
+	return langs, nil
+}
+
+// LanguageCounts reads the directory dir and returns the number of files
+// per language. Only the files visible after any language merge are counted,
+// so this can be used to spot missing translations.
+func LanguageCounts(fs afero.Fs, dir string) (map[string]int, error) {
+	fis, err := afero.ReadDir(fs, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int)
+
+	for _, fi := range fis {
+		if fi.IsDir() {
+			continue
+		}
+		if la, ok := fi.(LanguageAnnouncer); ok && la.Lang() != "" {
+			counts[la.Lang()]++
+		}
+	}
+
+	return counts, nil
+}
+
+// TranslationPaths reads the directory dir and returns a map from language
+// to the real filename of the files with the given translation base name,
+// e.g. "page" for "page.md" and "page.sv.md".
+// If there are several candidates for a language, e.g. "page.sv.md" and
+// "page.sv.html", the one with the highest weight wins, then the one preferred
+// by the InfixPolicy, then the one with the lowest filename.
+func TranslationPaths(fs afero.Fs, dir, translationBaseName string) (map[string]string, error) {
+	fis, err := afero.ReadDir(fs, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	winners := make(map[string]*LanguageFileInfo)
+
+	for _, fi := range fis {
+		lfi, ok := fi.(*LanguageFileInfo)
+		if !ok || lfi.IsDir() || lfi.TranslationBaseName() != translationBaseName {
+			continue
+		}
+
+		existing, found := winners[lfi.Lang()]
+		if !found || betterTranslation(lfi, existing) {
+			winners[lfi.Lang()] = lfi
+		}
+	}
+
+	paths := make(map[string]string)
+	for lang, lfi := range winners {
+		paths[lang] = lfi.Filename()
+	}
+
+	return paths, nil
+}
+
+func betterTranslation(candidate, existing *LanguageFileInfo) bool {
+	if candidate.outranks(existing) {
+		return true
+	}
+	if existing.outranks(candidate) {
+		return false
+	}
+	return candidate.Filename() < existing.Filename()
+}
+
+// Translation describes one translation of a page, see TranslationSet.
+type Translation struct {
+	Lang string
+
+	// Filename is the real filename.
+	Filename string
+
+	// Path is the slash separated logical path with the language in the
+	// filename, e.g. "sect/page.sv.md" for "sect/page.md" in the sv content
+	// dir. This is unique for every translation, see ChangedSince.
+	Path string
+
+	// Current is set for the translation that would be picked for its language
+	// (see TranslationPaths). The others are shadowed candidates.
+	Current bool
+}
+
+// TranslationSet returns all translations of the file with the given slash
+// separated logical path, e.g. "sect/page.md" or "sect/page.sv.md" as returned
+// in Translation.Path, including the file itself,
+// sorted by language with the current translation first. This is what is
+// needed to render a language switcher.
+// An os.ErrNotExist error is returned if the file does not exist.
+func TranslationSet(fs afero.Fs, logicalPath string) ([]Translation, error) {
+	dir, name := path.Split(logicalPath)
+
+	fis, err := afero.ReadDir(fs, filepath.FromSlash(dir))
+	if err != nil {
+		return nil, err
+	}
+
+	var translationBaseName string
+	for _, fi := range fis {
+		if lfi, ok := fi.(*LanguageFileInfo); ok && !lfi.IsDir() && (lfi.RealName() == name || lfi.virtualName == name) {
+			translationBaseName = lfi.TranslationBaseName()
+			break
+		}
+	}
+
+	if translationBaseName == "" {
+		return nil, &os.PathError{Op: "translations", Path: logicalPath, Err: os.ErrNotExist}
+	}
+
+	var candidates []*LanguageFileInfo
+	winners := make(map[string]*LanguageFileInfo)
+
+	for _, fi := range fis {
+		lfi, ok := fi.(*LanguageFileInfo)
+		if !ok || lfi.IsDir() || lfi.TranslationBaseName() != translationBaseName {
+			continue
+		}
+
+		candidates = append(candidates, lfi)
+
+		existing, found := winners[lfi.Lang()]
+		if !found || betterTranslation(lfi, existing) {
+			winners[lfi.Lang()] = lfi
+		}
+	}
+
+	set := make([]Translation, len(candidates))
+	for i, lfi := range candidates {
+		set[i] = Translation{
+			Lang:     lfi.Lang(),
+			Filename: lfi.Filename(),
+			Path:     uniqueLogicalPath(path.Join(dir, lfi.RealName()), lfi),
+			Current:  winners[lfi.Lang()] == lfi,
+		}
+	}
+
+	sort.Slice(set, func(i, j int) bool {
+		ti, tj := set[i], set[j]
+		if ti.Lang != tj.Lang {
+			return ti.Lang < tj.Lang
+		}
+		if ti.Current != tj.Current {
+			return ti.Current
+		}
+		return ti.Filename < tj.Filename
+	})
+
+	return set, nil
+}
+
+// CanonicalPath returns the language neutral path of fi relative to its
+// content dir, e.g. "sect/page.md" for both "sect/page.md" and "sect/page.sv.md",
+// which can be used to group translations.
+func CanonicalPath(fi *LanguageFileInfo) string {
+	if fi.IsDir() {
+		return fi.Path()
+	}
+	return filepath.Join(filepath.Dir(fi.Path()), fi.TranslationBaseName()+filepath.Ext(fi.RealName()))
+}
+
+// contentExts are the content file extensions, see helpers.GuessType.
+var contentExts = map[string]bool{
+	"md":       true,
+	"markdown": true,
+	"mdown":    true,
+	"asciidoc": true,
+	"adoc":     true,
+	"ad":       true,
+	"mmark":    true,
+	"rst":      true,
+	"pandoc":   true,
+	"pdc":      true,
+	"html":     true,
+	"htm":      true,
+	"org":      true,
+}
+
+// AmbiguousLanguageFiles walks the files below root and returns the slash
+// separated paths, relative to root, of the files with a language infix
+// registered in languages but with an extension that is not a content file
+// extension, e.g. "backup.no.zip" when Norwegian ("no") is configured.
+// LanguageFs treats these as translations, which is rarely what was intended.
+func AmbiguousLanguageFiles(fs afero.Fs, root string, languages map[string]bool) ([]string, error) {
+	var ambiguous []string
+
+	err := walkLogical(fs, root, func(filename, logical string, fi os.FileInfo) error {
+		if fi.IsDir() {
+			return nil
+		}
+
+		name := realName(fi)
+		ext := filepath.Ext(name)
+		lang := strings.TrimPrefix(filepath.Ext(strings.TrimSuffix(name, ext)), ".")
+
+		if languages[lang] && !contentExts[strings.ToLower(strings.TrimPrefix(ext, "."))] {
+			ambiguous = append(ambiguous, logical)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ambiguous, nil
+}
@@ -0,0 +1,273 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"crypto/md5"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestTranslationsFs creates a language composite filesystem with
+// sv on top, then en, then nn. The given files are written to the content
+// dir of the language given in the map key.
+func newTestTranslationsFs(t testing.TB, languages map[string]bool, files map[string][]string) afero.Fs {
+	var composite afero.Fs
+
+	for _, lang := range []string{"nn", "en", "sv"} {
+		m := afero.NewMemMapFs()
+		base := filepath.FromSlash("/content/" + lang)
+		for _, filename := range files[lang] {
+			if err := afero.WriteFile(m, filepath.Join(base, filepath.FromSlash(filename)), []byte(lang+" "+filename), 0755); err != nil {
+				t.Fatal(err)
+			}
+		}
+		lfs := NewLanguageFs(lang, languages, afero.NewBasePathFs(m, base))
+		if composite == nil {
+			composite = lfs
+		} else {
+			composite = NewLanguageCompositeFs(composite, lfs)
+		}
+	}
+
+	return composite
+}
+
+func TestLanguagesInTree(t *testing.T) {
+	assert := require.New(t)
+
+	languages := map[string]bool{
+		"sv": true,
+		"en": true,
+		"nn": true,
+		"de": true,
+	}
+
+	fs := newTestTranslationsFs(t, languages, map[string][]string{
+		"sv": {"sect/p1.md"},
+		"en": {"sect/p1.md", "sect/p2.md"},
+		"nn": {"p3.md"},
+	})
+
+	langs, err := LanguagesInTree(fs, "/")
+	assert.NoError(err)
+	assert.Equal([]string{"en", "nn", "sv"}, langs)
+
+	langs, err = LanguagesInTree(fs, "sect")
+	assert.NoError(err)
+	assert.Equal([]string{"en", "sv"}, langs)
+
+	langs, err = LanguagesInTree(afero.NewMemMapFs(), "/")
+	assert.NoError(err)
+	assert.Empty(langs)
+}
+
+func TestLanguageCounts(t *testing.T) {
+	assert := require.New(t)
+
+	languages := map[string]bool{
+		"sv": true,
+		"en": true,
+		"nn": true,
+		"de": true,
+	}
+
+	fs := newTestTranslationsFs(t, languages, map[string][]string{
+		"sv": {"sect/p1.md"},
+		"en": {"sect/p1.md", "sect/p2.md", "sect/p3.md", "sect/p4.md", "sect/p5.sv.md", "sect/sub/p6.md"},
+		"nn": {"sect/p1.md", "sect/p2.md"},
+	})
+
+	counts, err := LanguageCounts(fs, "sect")
+	assert.NoError(err)
+	assert.Equal(map[string]int{"en": 4, "sv": 2, "nn": 2}, counts)
+
+	counts, err = LanguageCounts(fs, filepath.FromSlash("sect/sub"))
+	assert.NoError(err)
+	assert.Equal(map[string]int{"en": 1}, counts)
+
+	_, err = LanguageCounts(fs, "nope")
+	assert.Error(err)
+}
+
+func TestTranslationPaths(t *testing.T) {
+	assert := require.New(t)
+
+	languages := map[string]bool{
+		"sv": true,
+		"en": true,
+		"nn": true,
+	}
+
+	fs := newTestTranslationsFs(t, languages, map[string][]string{
+		// page.sv.md in the en content dir is a candidate for sv, too.
+		"sv": {"sect/page.md", "sect/other.md"},
+		"en": {"sect/page.md", "sect/page.sv.md"},
+		"nn": {"sect/page.nn.md"},
+	})
+
+	paths, err := TranslationPaths(fs, "sect", "page")
+	assert.NoError(err)
+	assert.Equal(map[string]string{
+		"sv": filepath.FromSlash("/content/sv/sect/page.md"),
+		"en": filepath.FromSlash("/content/en/sect/page.md"),
+		"nn": filepath.FromSlash("/content/nn/sect/page.nn.md"),
+	}, paths)
+
+	paths, err = TranslationPaths(fs, "sect", "nope")
+	assert.NoError(err)
+	assert.Empty(paths)
+}
+
+func TestTranslationSet(t *testing.T) {
+	assert := require.New(t)
+
+	languages := map[string]bool{
+		"sv": true,
+		"en": true,
+		"nn": true,
+		"de": true,
+	}
+
+	fs := newTestTranslationsFs(t, languages, map[string][]string{
+		"sv": {"sect/page.md", "sect/page.html", "sect/other.md"},
+		// page.sv.md is shadowed by page.md in the sv content dir.
+		"en": {"sect/page.md", "sect/page.sv.md"},
+		"nn": {"sect/page.nn.md"},
+	})
+
+	expected := []Translation{
+		{Lang: "en", Filename: filepath.FromSlash("/content/en/sect/page.md"), Path: "sect/page.en.md", Current: true},
+		{Lang: "nn", Filename: filepath.FromSlash("/content/nn/sect/page.nn.md"), Path: "sect/page.nn.md", Current: true},
+		{Lang: "sv", Filename: filepath.FromSlash("/content/sv/sect/page.html"), Path: "sect/page.sv.html", Current: true},
+		// Same language and weight, page.html wins.
+		{Lang: "sv", Filename: filepath.FromSlash("/content/sv/sect/page.md"), Path: "sect/page.sv.md", Current: false},
+	}
+
+	for _, logicalPath := range []string{"sect/page.md", "sect/page.nn.md", "sect/page.html", "sect/page.en.md", "sect/page.sv.md", "sect/page.sv.html"} {
+		set, err := TranslationSet(fs, logicalPath)
+		assert.NoError(err)
+		assert.Equal(expected, set, logicalPath)
+	}
+
+	set, err := TranslationSet(fs, "sect/other.md")
+	assert.NoError(err)
+	assert.Equal([]Translation{
+		{Lang: "sv", Filename: filepath.FromSlash("/content/sv/sect/other.md"), Path: "sect/other.sv.md", Current: true},
+	}, set)
+
+	for _, logicalPath := range []string{"sect/page.de.md", "sect/other.en.md", "nope/page.md"} {
+		_, err = TranslationSet(fs, logicalPath)
+		assert.True(os.IsNotExist(err), logicalPath)
+	}
+}
+
+func TestTranslationSetSharedFilename(t *testing.T) {
+	assert := require.New(t)
+
+	languages := map[string]bool{
+		"sv": true,
+		"en": true,
+	}
+
+	fs := newTestTranslationsFs(t, languages, map[string][]string{
+		"sv": {"sect/page.md"},
+		"en": {"sect/page.md"},
+	})
+
+	expected := []Translation{
+		{Lang: "en", Filename: filepath.FromSlash("/content/en/sect/page.md"), Path: "sect/page.en.md", Current: true},
+		{Lang: "sv", Filename: filepath.FromSlash("/content/sv/sect/page.md"), Path: "sect/page.sv.md", Current: true},
+	}
+
+	// The paths reported by ChangedSince are accepted.
+	changed, _, err := ChangedSince(fs, "", nil, md5.New)
+	assert.NoError(err)
+	assert.Equal([]string{"sect/page.en.md", "sect/page.sv.md"}, changed)
+
+	for _, logicalPath := range append(changed, "sect/page.md") {
+		set, err := TranslationSet(fs, logicalPath)
+		assert.NoError(err)
+		assert.Equal(expected, set, logicalPath)
+	}
+}
+
+func TestCanonicalPath(t *testing.T) {
+	assert := require.New(t)
+
+	languages := map[string]bool{
+		"sv": true,
+		"en": true,
+	}
+
+	lfs := NewLanguageFs("sv", languages, afero.NewBasePathFs(afero.NewMemMapFs(), filepath.FromSlash("/content/sv")))
+
+	for _, test := range []struct {
+		filename string
+		expected string
+	}{
+		{"sect/page.md", "sect/page.md"},
+		{"sect/page.sv.md", "sect/page.md"},
+		{"sect/page.en.md", "sect/page.md"},
+		{"sect/archive.tar.gz", "sect/archive.tar.gz"},
+		{"sect/archive.tar.en.gz", "sect/archive.tar.gz"},
+		{"page.en.md", "page.md"},
+		{"sect", "sect"},
+	} {
+		filename := filepath.FromSlash(test.filename)
+		if test.filename != "sect" {
+			assert.NoError(afero.WriteFile(lfs, filename, []byte("content"), 0755))
+		}
+		fi, err := lfs.Stat(filename)
+		assert.NoError(err)
+		assert.Equal(filepath.FromSlash(test.expected), CanonicalPath(fi.(*LanguageFileInfo)), test.filename)
+	}
+}
+
+func TestAmbiguousLanguageFiles(t *testing.T) {
+	assert := require.New(t)
+
+	languages := map[string]bool{
+		"en": true,
+		"no": true,
+		"is": true,
+	}
+
+	m := afero.NewMemMapFs()
+	for _, filename := range []string{
+		"post.md",
+		"post.no.md",
+		"post.is.HTML",
+		"sect/backup.no.zip",
+		"sect/config.is.json",
+		"sect/jquery.min.js",
+		"sect/data.no",
+	} {
+		assert.NoError(afero.WriteFile(m, filepath.Join("content", filepath.FromSlash(filename)), []byte(filename), 0755))
+	}
+
+	ambiguous, err := AmbiguousLanguageFiles(m, "content", languages)
+	assert.NoError(err)
+	assert.Equal([]string{"sect/backup.no.zip", "sect/config.is.json"}, ambiguous)
+
+	// Real names are used in language filesystems.
+	fs := NewLanguageFs("en", languages, afero.NewBasePathFs(m, "content"))
+	ambiguous, err = AmbiguousLanguageFiles(fs, "sect", languages)
+	assert.NoError(err)
+	assert.Equal([]string{"backup.no.zip", "config.is.json"}, ambiguous)
+}
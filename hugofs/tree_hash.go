@@ -0,0 +1,116 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"encoding/hex"
+	"hash"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/spf13/afero"
+)
+
+// TreeHash walks the files below root in lexical order and folds the real
+// filename and the content hash of every file into one digest. Only the
+// files visible in the walked filesystem count, so files shadowed by the
+// composite filesystems do not affect the result.
+// This is useful as a cache key.
+func TreeHash(fs afero.Fs, root string, hasher func() hash.Hash) ([]byte, error) {
+	tree := hasher()
+
+	err := walkLogical(fs, root, func(filename, logical string, fi os.FileInfo) error {
+		if fi.IsDir() {
+			return nil
+		}
+
+		sum, err := hashFile(fs, filename, hasher())
+		if err != nil {
+			return err
+		}
+
+		io.WriteString(tree, realFilename(fi, filename))
+		tree.Write(sum)
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return tree.Sum(nil), nil
+}
+
+// ChangedSince walks the files below root and compares them with known,
+// a map from slash separated path relative to root to the hex encoded content
+// hash from a previous build, as created with hasher.
+// Files from a language filesystem have the language in the filename,
+// e.g. "sect/page.sv.md".
+// It returns the paths of the files that are new or have a different hash
+// (changed) and the paths in known that are not found anymore (removed).
+// Both slices are sorted.
+func ChangedSince(fs afero.Fs, root string, known map[string]string, hasher func() hash.Hash) (changed, removed []string, err error) {
+	seen := make(map[string]bool)
+
+	err = walkLogical(fs, root, func(filename, logical string, fi os.FileInfo) error {
+		if fi.IsDir() {
+			return nil
+		}
+
+		logical = uniqueLogicalPath(logical, fi)
+		seen[logical] = true
+
+		sum, err := hashFile(fs, filename, hasher())
+		if err != nil {
+			return err
+		}
+
+		if known[logical] != hex.EncodeToString(sum) {
+			changed = append(changed, logical)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for p := range known {
+		if !seen[p] {
+			removed = append(removed, p)
+		}
+	}
+
+	sort.Strings(changed)
+	sort.Strings(removed)
+
+	return
+}
+
+func hashFile(fs afero.Fs, filename string, h hash.Hash) ([]byte, error) {
+	f, err := fs.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+
+	return h.Sum(nil), nil
+}
@@ -0,0 +1,130 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTreeHash(t *testing.T) {
+	assert := require.New(t)
+
+	base := afero.NewMemMapFs()
+	overlay := afero.NewMemMapFs()
+	fs := afero.NewCopyOnWriteFs(base, overlay)
+
+	afero.WriteFile(base, filepath.FromSlash("/content/sect/p1.md"), []byte("base p1"), 0755)
+	afero.WriteFile(base, filepath.FromSlash("/content/sect/p2.md"), []byte("base p2"), 0755)
+	afero.WriteFile(overlay, filepath.FromSlash("/content/sect/p1.md"), []byte("overlay p1"), 0755)
+
+	root := filepath.FromSlash("/content")
+
+	h1, err := TreeHash(fs, root, md5.New)
+	assert.NoError(err)
+	h2, err := TreeHash(fs, root, md5.New)
+	assert.NoError(err)
+	assert.Equal(h1, h2)
+
+	// p1.md in base is shadowed by the overlay.
+	afero.WriteFile(base, filepath.FromSlash("/content/sect/p1.md"), []byte("base p1 edited"), 0755)
+	h3, err := TreeHash(fs, root, md5.New)
+	assert.NoError(err)
+	assert.Equal(h1, h3)
+
+	afero.WriteFile(overlay, filepath.FromSlash("/content/sect/p1.md"), []byte("overlay p1 edited"), 0755)
+	h4, err := TreeHash(fs, root, md5.New)
+	assert.NoError(err)
+	assert.NotEqual(h1, h4)
+
+	afero.WriteFile(base, filepath.FromSlash("/content/sect/p2.md"), []byte("base p2 edited"), 0755)
+	h5, err := TreeHash(fs, root, md5.New)
+	assert.NoError(err)
+	assert.NotEqual(h4, h5)
+}
+
+func TestChangedSince(t *testing.T) {
+	assert := require.New(t)
+
+	fs := newTestUnionFs(t)
+	root := filepath.FromSlash("/content")
+
+	md5Hex := func(s string) string {
+		sum := md5.Sum([]byte(s))
+		return hex.EncodeToString(sum[:])
+	}
+
+	known := map[string]string{
+		"sect/p1.md": md5Hex("overlay p1"),
+		"sect/p2.md": md5Hex("base p2 before"),
+		"sect/p3.md": md5Hex("base p3"),
+	}
+
+	changed, removed, err := ChangedSince(fs, root, known, md5.New)
+	assert.NoError(err)
+	assert.Equal([]string{"about.md", "sect/p2.md"}, changed)
+	assert.Equal([]string{"sect/p3.md"}, removed)
+
+	known = map[string]string{
+		"about.md":   md5Hex("overlay about"),
+		"sect/p1.md": md5Hex("overlay p1"),
+		"sect/p2.md": md5Hex("base p2"),
+	}
+
+	changed, removed, err = ChangedSince(fs, root, known, md5.New)
+	assert.NoError(err)
+	assert.Empty(changed)
+	assert.Empty(removed)
+}
+
+func TestChangedSinceLanguageComposite(t *testing.T) {
+	assert := require.New(t)
+
+	languages := map[string]bool{
+		"sv": true,
+		"en": true,
+		"nn": true,
+	}
+
+	fs := newTestTranslationsFs(t, languages, map[string][]string{
+		"sv": {"sect/page.md"},
+		"en": {"sect/page.md", "sect/f.nn.md"},
+	})
+
+	md5Hex := func(s string) string {
+		sum := md5.Sum([]byte(s))
+		return hex.EncodeToString(sum[:])
+	}
+
+	changed, removed, err := ChangedSince(fs, "sect", nil, md5.New)
+	assert.NoError(err)
+	assert.Equal([]string{"f.nn.md", "page.en.md", "page.sv.md"}, changed)
+	assert.Empty(removed)
+
+	known := map[string]string{
+		"f.nn.md":    md5Hex("en sect/f.nn.md"),
+		"page.en.md": md5Hex("en sect/page.md"),
+		"page.sv.md": md5Hex("sv sect/page.md before"),
+	}
+
+	changed, removed, err = ChangedSince(fs, "sect", known, md5.New)
+	assert.NoError(err)
+	assert.Equal([]string{"page.sv.md"}, changed)
+	assert.Empty(removed)
+}
@@ -0,0 +1,108 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"os"
+	"path/filepath"
+	"unicode/utf8"
+
+	"github.com/spf13/afero"
+)
+
+var (
+	_ afero.Fs      = (*utf8FilterFs)(nil)
+	_ afero.Lstater = (*utf8FilterFs)(nil)
+)
+
+type utf8FilterFs struct {
+	afero.Fs
+	onInvalid func(name string)
+}
+
+// NewUTF8FilterFs creates a new filesystem that hides files and directories
+// with names that are not valid UTF-8. Such entries are dropped from Readdir
+// and onInvalid, if set, is called with the entry's real filename.
+// Open and Stat of a name that is not valid UTF-8 return os.ErrNotExist,
+// so the content of a hidden directory is not reachable either.
+func NewUTF8FilterFs(fs afero.Fs, onInvalid func(name string)) afero.Fs {
+	return &utf8FilterFs{Fs: fs, onInvalid: onInvalid}
+}
+
+func (fs *utf8FilterFs) Stat(name string) (os.FileInfo, error) {
+	if !utf8.ValidString(name) {
+		return nil, &os.PathError{Op: "stat", Path: name, Err: os.ErrNotExist}
+	}
+	return fs.Fs.Stat(name)
+}
+
+func (fs *utf8FilterFs) LstatIfPossible(name string) (os.FileInfo, bool, error) {
+	if !utf8.ValidString(name) {
+		return nil, false, &os.PathError{Op: "lstat", Path: name, Err: os.ErrNotExist}
+	}
+
+	if lfs, ok := fs.Fs.(afero.Lstater); ok {
+		return lfs.LstatIfPossible(name)
+	}
+
+	fi, err := fs.Fs.Stat(name)
+	return fi, false, err
+}
+
+func (fs *utf8FilterFs) Open(name string) (afero.File, error) {
+	if !utf8.ValidString(name) {
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+
+	f, err := fs.Fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	if !fi.IsDir() {
+		return f, nil
+	}
+
+	return &filteringDir{File: f, filter: func(fi os.FileInfo) os.FileInfo {
+		if utf8.ValidString(realName(fi)) {
+			return fi
+		}
+		if fs.onInvalid != nil {
+			fs.onInvalid(realFilename(fi, filepath.Join(name, fi.Name())))
+		}
+		return nil
+	}}, nil
+}
+
+func (fs *utf8FilterFs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	if !isWrite(flag) && flag&os.O_CREATE == 0 {
+		return fs.Open(name)
+	}
+
+	if !utf8.ValidString(name) {
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+
+	return fs.Fs.OpenFile(name, flag, perm)
+}
+
+func (fs *utf8FilterFs) Name() string {
+	return "utf8FilterFs"
+}
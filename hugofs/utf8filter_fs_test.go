@@ -0,0 +1,97 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUTF8FilterFs(t *testing.T) {
+	assert := require.New(t)
+
+	invalid := "p\xff\xfe.md"
+
+	m := afero.NewMemMapFs()
+	for _, filename := range []string{"p1.md", "påske.md", invalid, "bad\xffdir/p2.md", "sect/p3.md"} {
+		assert.NoError(afero.WriteFile(m, filepath.Join("content", filepath.FromSlash(filename)), []byte(filename), 0755))
+	}
+
+	var warnings []string
+	fs := NewUTF8FilterFs(m, func(name string) {
+		warnings = append(warnings, name)
+	})
+
+	fis, err := afero.ReadDir(fs, "content")
+	assert.NoError(err)
+	var names []string
+	for _, fi := range fis {
+		names = append(names, fi.Name())
+	}
+	assert.Equal([]string{"p1.md", "påske.md", "sect"}, names)
+	assert.Equal([]string{filepath.Join("content", "bad\xffdir"), filepath.Join("content", invalid)}, warnings)
+
+	for _, filename := range []string{invalid, "bad\xffdir", "bad\xffdir/p2.md"} {
+		filename = filepath.Join("content", filepath.FromSlash(filename))
+		_, err := fs.Open(filename)
+		assert.True(os.IsNotExist(err), filename)
+		_, err = fs.Stat(filename)
+		assert.True(os.IsNotExist(err), filename)
+		_, _, err = fs.(afero.Lstater).LstatIfPossible(filename)
+		assert.True(os.IsNotExist(err), filename)
+	}
+
+	b, err := afero.ReadFile(fs, filepath.Join("content", "påske.md"))
+	assert.NoError(err)
+	assert.Equal("påske.md", string(b))
+
+	// A nil callback is fine.
+	fis, err = afero.ReadDir(NewUTF8FilterFs(m, nil), "content")
+	assert.NoError(err)
+	assert.Len(fis, 3)
+}
+
+func TestUTF8FilterFsLanguageComposite(t *testing.T) {
+	assert := require.New(t)
+
+	languages := map[string]bool{
+		"sv": true,
+		"en": true,
+		"nn": true,
+	}
+
+	composite := newTestTranslationsFs(t, languages, map[string][]string{
+		"sv": {"sect/page.md", "sect/p\xff.md"},
+		"en": {"sect/page.md", "sect/f.nn.md"},
+	})
+
+	var warnings []string
+	fs := NewUTF8FilterFs(composite, func(name string) {
+		warnings = append(warnings, name)
+	})
+
+	fis, err := afero.ReadDir(fs, "sect")
+	assert.NoError(err)
+	assert.Len(fis, 3)
+	for _, fi := range fis {
+		_, ok := fi.(*LanguageFileInfo)
+		assert.True(ok, fi.Name())
+	}
+
+	assert.Equal([]string{filepath.FromSlash("/content/sv/sect/p\xff.md")}, warnings)
+}
@@ -0,0 +1,86 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// logicalWalkFunc is called for every file and directory below the root
+// in walkLogical. filename is the name to use when opening the file in the
+// walked filesystem, logical is the slash separated path relative to the root
+// using the real file names, e.g. "sect/page.md" and not "sect/__hugofs_sv_page.md".
+type logicalWalkFunc func(filename, logical string, fi os.FileInfo) error
+
+// walkLogical walks fs below root in lexical order. The root itself is not
+// passed to walkFn.
+func walkLogical(fs afero.Fs, root string, walkFn logicalWalkFunc) error {
+	// Note that we walk the root as given, as some filesystems (e.g. RootMappingFs)
+	// treat "" and "." differently.
+	cleanRoot := filepath.Clean(root)
+	return afero.Walk(fs, root, func(filename string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		filename = filepath.Clean(filename)
+		if filename == cleanRoot {
+			return nil
+		}
+
+		rel := filename
+		if cleanRoot != "." {
+			rel = strings.TrimPrefix(rel, cleanRoot)
+		}
+		rel = strings.TrimPrefix(filepath.ToSlash(rel), "/")
+		logical := path.Join(path.Dir(rel), realName(fi))
+
+		return walkFn(filename, logical, fi)
+	})
+}
+
+// uniqueLogicalPath returns logical with the language in the filename for
+// files from a language filesystem, e.g. "sect/page.sv.md" for "sect/page.md"
+// in the sv content dir. Translations with the same name in different content
+// dirs share the logical path, but this path is unique in a merged view.
+func uniqueLogicalPath(logical string, fi os.FileInfo) string {
+	if lfi, ok := fi.(*LanguageFileInfo); ok && !lfi.IsDir() {
+		return path.Join(path.Dir(logical), lfi.virtualName)
+	}
+	return logical
+}
+
+// realName returns the file's base name in its original form.
+func realName(fi os.FileInfo) string {
+	if fp, ok := fi.(FilePather); ok {
+		return fp.RealName()
+	}
+	return fi.Name()
+}
+
+// realFilename returns the file's full filename in the underlying filesystem
+// if that information is available, else the provided filename.
+func realFilename(fi os.FileInfo, filename string) string {
+	switch v := fi.(type) {
+	case FilePather:
+		return v.Filename()
+	case RealFilenameInfo:
+		return v.RealFilename()
+	}
+	return filename
+}
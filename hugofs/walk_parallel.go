@@ -0,0 +1,100 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"errors"
+	"os"
+	"sync"
+
+	"github.com/spf13/afero"
+)
+
+var errWalkAborted = errors.New("walk aborted")
+
+// WalkParallel walks the files below root and calls fn for every file,
+// but not for directories, from workers goroutines. The walk itself is
+// sequential, so fn gets every file visible in fs exactly once, but in no
+// particular order. filename is the name to use when opening the file in fs.
+// The first error returned from fn stops the walk and is returned.
+func WalkParallel(fs afero.Fs, root string, workers int, fn func(filename string, fi os.FileInfo) error) error {
+	if workers < 1 {
+		workers = 1
+	}
+
+	type job struct {
+		filename string
+		fi       os.FileInfo
+	}
+
+	var (
+		jobs     = make(chan job)
+		done     = make(chan struct{})
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+
+	setErr := func(err error) {
+		errOnce.Do(func() {
+			firstErr = err
+			close(done)
+		})
+	}
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				select {
+				case <-done:
+					// Drain the remaining jobs without calling fn.
+					continue
+				default:
+				}
+				if err := fn(j.filename, j.fi); err != nil {
+					setErr(err)
+				}
+			}
+		}()
+	}
+
+	err := walkLogical(fs, root, func(filename, logical string, fi os.FileInfo) error {
+		if fi.IsDir() {
+			return nil
+		}
+		// Check done first, as select picks a ready case at random.
+		select {
+		case <-done:
+			return errWalkAborted
+		default:
+		}
+		select {
+		case jobs <- job{filename: filename, fi: fi}:
+			return nil
+		case <-done:
+			return errWalkAborted
+		}
+	})
+
+	close(jobs)
+	wg.Wait()
+
+	if err != nil && err != errWalkAborted {
+		setErr(err)
+	}
+
+	return firstErr
+}
@@ -0,0 +1,86 @@
+// Copyright 2019 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hugofs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalkParallel(t *testing.T) {
+	assert := require.New(t)
+
+	m := afero.NewMemMapFs()
+	for i := 0; i < 200; i++ {
+		filename := filepath.FromSlash(fmt.Sprintf("content/sect%d/p%d.md", i%10, i))
+		assert.NoError(afero.WriteFile(m, filename, []byte("content"), 0755))
+	}
+
+	var (
+		mu      sync.Mutex
+		visited = make(map[string]int)
+	)
+
+	err := WalkParallel(m, "content", 8, func(filename string, fi os.FileInfo) error {
+		if fi.IsDir() {
+			return errors.New("got dir")
+		}
+		mu.Lock()
+		visited[filename]++
+		mu.Unlock()
+		return nil
+	})
+
+	assert.NoError(err)
+	assert.Len(visited, 200)
+	for filename, count := range visited {
+		assert.Equal(1, count, filename)
+	}
+
+	// The first error aborts the walk. Every worker can at most be in fn
+	// once before done is closed.
+	var calls int32
+	errFail := errors.New("fail")
+	err = WalkParallel(m, "content", 4, func(filename string, fi os.FileInfo) error {
+		atomic.AddInt32(&calls, 1)
+		return errFail
+	})
+
+	assert.Equal(errFail, err)
+	assert.True(atomic.LoadInt32(&calls) <= 4, "calls: %d", calls)
+
+	// Walk errors are returned.
+	err = WalkParallel(m, "nope", 4, func(filename string, fi os.FileInfo) error {
+		return nil
+	})
+	assert.Error(err)
+
+	// A shadowed file is visited once.
+	fs := newTestUnionFs(t)
+	count := 0
+	err = WalkParallel(fs, filepath.FromSlash("/content"), 1, func(filename string, fi os.FileInfo) error {
+		count++
+		return nil
+	})
+	assert.NoError(err)
+	assert.Equal(3, count)
+}